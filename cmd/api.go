@@ -1,14 +1,17 @@
 package cmd
 
 import (
-	"log"
-	"os"
-	"os/signal"
-	"syscall"
+	"context"
+	"errors"
+	"log/slog"
 	"time"
 
-	"github.com/mirusky-dev/challenge-18/core/background"
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mirusky-dev/challenge-18/container"
 	"github.com/mirusky-dev/challenge-18/core/env"
+	"github.com/mirusky-dev/challenge-18/core/lifecycle"
+	"github.com/mirusky-dev/challenge-18/core/tracing"
 	"github.com/mirusky-dev/challenge-18/router"
 	"github.com/spf13/cobra"
 )
@@ -36,35 +39,26 @@ func api(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	backgroundJobClient, err := background.NewClient(config)
-	if err != nil {
-		log.Fatal("Failed to initialize background jobs", err)
+	if err := setupLogger(config); err != nil {
 		return err
 	}
 
-	app := router.Setup(config, backgroundJobClient)
-
-	// Listen from a different goroutine
-	go func() {
-		if err := app.Listen(":" + config.Port); err != nil {
-			log.Panic(err)
-		}
-	}()
-
-	c := make(chan os.Signal, 1)                    // Create channel to signify a signal being sent
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM) // When an interrupt or termination signal is sent, notify the channel
+	lc := lifecycle.New(slog.Default(), 10*time.Second)
 
-	<-c // This blocks the main thread until an interrupt is received
-	log.Println("[api] Gracefully shutting down...")
-	app.ShutdownWithTimeout(10 * time.Second)
+	shutdownTracing, err := tracing.Setup(context.Background(), config, "gobp-api")
+	if err != nil {
+		return err
+	}
 
-	log.Println("[api] Running cleanup tasks...")
+	// Added first, so the spans of the shutdown are still exported
+	lc.Append(lifecycle.Hook{Name: "tracing", OnStop: shutdownTracing})
 
-	// Your cleanup tasks go here
-	// db.Close()
-	// redisConn.Close()
+	deps := container.New(config, "api", lc)
 
-	log.Println("[api] Server was successful shutdown.")
+	if _, err := container.Resolve(func() *fiber.App { return router.Setup(deps) }); err != nil {
+		return errors.Join(err, lc.Stop(context.Background()))
+	}
 
-	return nil
+	// Blocks until an interrupt or termination signal, the in-flight requests and the streams are drained
+	return lc.Run(context.Background(), shutdownTimeout)
 }
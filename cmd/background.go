@@ -1,22 +1,27 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"log"
-	"os"
-	"os/signal"
-	"syscall"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
 
 	"github.com/hibiken/asynq"
 	"github.com/spf13/cobra"
-	"gorm.io/driver/mysql"
-	"gorm.io/gorm"
 
+	"github.com/mirusky-dev/challenge-18/container"
 	"github.com/mirusky-dev/challenge-18/core/background"
 	"github.com/mirusky-dev/challenge-18/core/background/events"
-	"github.com/mirusky-dev/challenge-18/core/background/handlers"
 	"github.com/mirusky-dev/challenge-18/core/env"
-	"github.com/mirusky-dev/challenge-18/core/mailer"
-	"github.com/mirusky-dev/challenge-18/repositories"
+	"github.com/mirusky-dev/challenge-18/core/health"
+	"github.com/mirusky-dev/challenge-18/core/lifecycle"
+	"github.com/mirusky-dev/challenge-18/core/metrics"
+	"github.com/mirusky-dev/challenge-18/core/tracing"
+	"github.com/mirusky-dev/challenge-18/models/entities"
+	"github.com/mirusky-dev/challenge-18/worker"
 )
 
 func newCmdBackground() *cobra.Command {
@@ -28,37 +33,52 @@ func newCmdBackground() *cobra.Command {
 		RunE:  backgroundJobs,
 	}
 
+	cmd.AddCommand(
+		newCmdJobs(),
+	)
+
 	return cmd
 }
 
-func setupBackground(config env.Config, backgroundClient *asynq.Client) (*asynq.Server, *asynq.ServeMux, error) {
-	svr, mux, err := background.NewServerMux(config)
-	if err != nil {
-		return nil, nil, err
+// newHTTPServer serves the worker metrics and health checks, the worker has no other HTTP endpoint
+func newHTTPServer(config env.Config, checker *health.Checker) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	checker.Register(mux)
+
+	return &http.Server{
+		Addr:              ":" + config.MetricsPort,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
 	}
+}
 
-	db, err := gorm.Open(mysql.Open(config.DatabaseURL), &gorm.Config{})
+// setupScheduler registers the periodic tasks
+func setupScheduler(config env.Config) (*asynq.Scheduler, error) {
+	scheduler, err := background.NewScheduler(config)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	mailer, _ := mailer.NewNoopMailer(config)
-
-	userRepository := repositories.NewUserRepository(db)
-	taskRepository := repositories.NewTaskRepository(db)
+	daily, err := events.NewDigestRun(entities.DigestDaily)
+	if err != nil {
+		return nil, err
+	}
 
-	ctrl := handlers.Controller{
-		Config:           config,
-		Mailer:           mailer,
-		BackgroundClient: backgroundClient,
+	if _, err := scheduler.Register(config.DigestDailyCron, daily); err != nil {
+		return nil, err
+	}
 
-		UserRepository: userRepository,
-		TaskRepository: taskRepository,
+	weekly, err := events.NewDigestRun(entities.DigestWeekly)
+	if err != nil {
+		return nil, err
 	}
 
-	mux.HandleFunc(events.TypeTaskCompleted, ctrl.HandleTaskCompleted)
+	if _, err := scheduler.Register(config.DigestWeeklyCron, weekly); err != nil {
+		return nil, err
+	}
 
-	return svr, mux, err
+	return scheduler, nil
 }
 
 func backgroundJobs(cmd *cobra.Command, args []string) error {
@@ -69,39 +89,72 @@ func backgroundJobs(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	backgroundJobClient, err := background.NewClient(config)
-	if err != nil {
+	if err := setupLogger(config); err != nil {
 		return err
 	}
 
-	svr, mux, err := setupBackground(config, backgroundJobClient)
+	lc := lifecycle.New(slog.Default(), 10*time.Second)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), config, "gobp-background")
 	if err != nil {
 		return err
 	}
 
-	// Listen from a different goroutine
-	go func() {
-		if err := svr.Start(mux); err != nil {
-			log.Panic(err)
-		}
-	}()
-
-	c := make(chan os.Signal, 1)                    // Create channel to signify a signal being sent
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM) // When an interrupt or termination signal is sent, notify the channel
-
-	<-c // This blocks the main thread until an interrupt is received
-	log.Println("[background] Gracefully shutting down...")
-	svr.Shutdown()
-
-	log.Println("[background] Running cleanup tasks...")
+	lc.Append(lifecycle.Hook{Name: "tracing", OnStop: shutdownTracing})
 
-	// Your cleanup tasks go here
-	// db.Close()
-	// redisConn.Close()
+	deps := container.New(config, "background", lc)
 
-	backgroundJobClient.Close()
+	svr, mux, err := worker.Setup(deps)
+	if err != nil {
+		return errors.Join(err, lc.Stop(context.Background()))
+	}
 
-	log.Println("[background] Server was successful shutdown.")
+	scheduler, err := setupScheduler(config)
+	if err != nil {
+		return errors.Join(err, lc.Stop(context.Background()))
+	}
 
-	return nil
+	httpServer := newHTTPServer(config, deps.Health)
+
+	// Stopped in reverse: the worker finishes the active tasks before the scheduler and the /metrics server stop
+	lc.Append(lifecycle.Hook{
+		Name: "http",
+		OnStart: func(ctx context.Context) error {
+			ln, err := net.Listen("tcp", httpServer.Addr)
+			if err != nil {
+				return err
+			}
+
+			go func() {
+				if err := httpServer.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					slog.Error("background: http server stopped", "error", err)
+				}
+			}()
+
+			return nil
+		},
+		OnStop: httpServer.Shutdown,
+	})
+
+	lc.Append(lifecycle.Hook{
+		Name:    "scheduler",
+		OnStart: func(ctx context.Context) error { return scheduler.Start() },
+		OnStop: func(ctx context.Context) error {
+			scheduler.Shutdown()
+			return nil
+		},
+	})
+
+	lc.Append(lifecycle.Hook{
+		Name:    "worker",
+		OnStart: func(ctx context.Context) error { return svr.Start(mux) },
+		OnStop: func(ctx context.Context) error {
+			// Waits for the active tasks up to the ShutdownTimeout of the server, the others are retried later
+			svr.Shutdown()
+			return nil
+		},
+	})
+
+	// Blocks until an interrupt or termination signal
+	return lc.Run(context.Background(), shutdownTimeout)
 }
@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/spf13/cobra"
+
+	"github.com/mirusky-dev/challenge-18/core/background"
+	"github.com/mirusky-dev/challenge-18/core/env"
+)
+
+const (
+	stateArchived  = "archived"
+	stateRetry     = "retry"
+	statePending   = "pending"
+	stateScheduled = "scheduled"
+	stateActive    = "active"
+	stateCompleted = "completed"
+)
+
+func newCmdJobs() *cobra.Command {
+
+	cmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "Manages the background jobs",
+		Long:  "Lists, retries, archives and purges the background jobs, e.g: the ones that exhausted their retries (archived).",
+	}
+
+	cmd.AddCommand(
+		newCmdJobsList(),
+		newCmdJobsRetry(),
+		newCmdJobsArchive(),
+		newCmdJobsPurge(),
+	)
+
+	return cmd
+}
+
+func newInspector() (*asynq.Inspector, error) {
+	config, err := env.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	return background.NewInspector(config)
+}
+
+// queuesOf returns the given queue or every known queue when it's empty
+func queuesOf(inspector *asynq.Inspector, queue string) ([]string, error) {
+	if queue != "" {
+		return []string{queue}, nil
+	}
+
+	return inspector.Queues()
+}
+
+func listTasks(inspector *asynq.Inspector, queue, state string, limit int) ([]*asynq.TaskInfo, error) {
+	opts := []asynq.ListOption{asynq.PageSize(limit), asynq.Page(1)}
+
+	var tasks []*asynq.TaskInfo
+	var err error
+
+	switch state {
+	case stateArchived:
+		tasks, err = inspector.ListArchivedTasks(queue, opts...)
+	case stateRetry:
+		tasks, err = inspector.ListRetryTasks(queue, opts...)
+	case statePending:
+		tasks, err = inspector.ListPendingTasks(queue, opts...)
+	case stateScheduled:
+		tasks, err = inspector.ListScheduledTasks(queue, opts...)
+	case stateActive:
+		tasks, err = inspector.ListActiveTasks(queue, opts...)
+	case stateCompleted:
+		tasks, err = inspector.ListCompletedTasks(queue, opts...)
+	default:
+		return nil, fmt.Errorf("unknown state %q", state)
+	}
+
+	// A queue that never received a task doesn't exist yet
+	if errors.Is(err, asynq.ErrQueueNotFound) {
+		return nil, nil
+	}
+
+	return tasks, err
+}
+
+func newCmdJobsList() *cobra.Command {
+	var queue, state string
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Lists the jobs of a state",
+		Example: `  background jobs list
+  background jobs list --state retry --queue mail`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inspector, err := newInspector()
+			if err != nil {
+				return err
+			}
+			defer inspector.Close()
+
+			queues, err := queuesOf(inspector, queue)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tTYPE\tQUEUE\tRETRIED\tLAST FAILED AT\tLAST ERROR")
+
+			for _, q := range queues {
+				tasks, err := listTasks(inspector, q, state, limit)
+				if err != nil {
+					return err
+				}
+
+				for _, task := range tasks {
+					lastFailedAt := "-"
+					if !task.LastFailedAt.IsZero() {
+						lastFailedAt = task.LastFailedAt.Format(time.RFC3339)
+					}
+
+					fmt.Fprintf(w, "%s\t%s\t%s\t%d/%d\t%s\t%s\n", task.ID, task.Type, task.Queue, task.Retried, task.MaxRetry, lastFailedAt, truncate(task.LastErr, 80))
+				}
+			}
+
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVarP(&queue, "queue", "q", "", "queue name, every queue when empty")
+	cmd.Flags().StringVarP(&state, "state", "s", stateArchived, "archived, retry, pending, scheduled, active or completed")
+	cmd.Flags().IntVarP(&limit, "limit", "l", 50, "max jobs per queue")
+
+	return cmd
+}
+
+func newCmdJobsRetry() *cobra.Command {
+	var queue string
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "retry [id]",
+		Short: "Runs an archived or retrying job right away",
+		Example: `  background jobs retry 2f436752-0e23-4eb1-9963-ee8e9d04e972 --queue mail
+  background jobs retry --all --queue mail`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if queue == "" {
+				return errors.New("--queue is required")
+			}
+
+			inspector, err := newInspector()
+			if err != nil {
+				return err
+			}
+			defer inspector.Close()
+
+			if all {
+				n, err := inspector.RunAllArchivedTasks(queue)
+				if err != nil {
+					return err
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "%d jobs enqueued\n", n)
+				return nil
+			}
+
+			if len(args) == 0 {
+				return errors.New("a job id or --all is required")
+			}
+
+			if err := inspector.RunTask(queue, args[0]); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), "job enqueued")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&queue, "queue", "q", "", "queue name")
+	cmd.Flags().BoolVar(&all, "all", false, "retries every archived job of the queue")
+
+	return cmd
+}
+
+func newCmdJobsArchive() *cobra.Command {
+	var queue string
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "archive [id]",
+		Short: "Archives a pending, scheduled or retrying job, so it won't run until retried",
+		Example: `  background jobs archive 2f436752-0e23-4eb1-9963-ee8e9d04e972 --queue webhook
+  background jobs archive --all --queue webhook`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if queue == "" {
+				return errors.New("--queue is required")
+			}
+
+			inspector, err := newInspector()
+			if err != nil {
+				return err
+			}
+			defer inspector.Close()
+
+			if all {
+				n, err := inspector.ArchiveAllRetryTasks(queue)
+				if err != nil {
+					return err
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "%d retrying jobs archived\n", n)
+				return nil
+			}
+
+			if len(args) == 0 {
+				return errors.New("a job id or --all is required")
+			}
+
+			if err := inspector.ArchiveTask(queue, args[0]); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), "job archived")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&queue, "queue", "q", "", "queue name")
+	cmd.Flags().BoolVar(&all, "all", false, "archives every retrying job of the queue")
+
+	return cmd
+}
+
+func newCmdJobsPurge() *cobra.Command {
+	var queue, state string
+
+	cmd := &cobra.Command{
+		Use:   "purge [id]",
+		Short: "Deletes a job, or every job of a state",
+		Example: `  background jobs purge 2f436752-0e23-4eb1-9963-ee8e9d04e972 --queue mail
+  background jobs purge --state archived`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inspector, err := newInspector()
+			if err != nil {
+				return err
+			}
+			defer inspector.Close()
+
+			if len(args) == 1 {
+				if queue == "" {
+					return errors.New("--queue is required to delete a job")
+				}
+
+				if err := inspector.DeleteTask(queue, args[0]); err != nil {
+					return err
+				}
+
+				fmt.Fprintln(cmd.OutOrStdout(), "job deleted")
+				return nil
+			}
+
+			queues, err := queuesOf(inspector, queue)
+			if err != nil {
+				return err
+			}
+
+			total := 0
+			for _, q := range queues {
+				var n int
+
+				switch state {
+				case stateArchived:
+					n, err = inspector.DeleteAllArchivedTasks(q)
+				case stateCompleted:
+					n, err = inspector.DeleteAllCompletedTasks(q)
+				case stateRetry:
+					n, err = inspector.DeleteAllRetryTasks(q)
+				case statePending:
+					n, err = inspector.DeleteAllPendingTasks(q)
+				case stateScheduled:
+					n, err = inspector.DeleteAllScheduledTasks(q)
+				default:
+					return fmt.Errorf("can't purge the state %q", state)
+				}
+
+				if err != nil && !errors.Is(err, asynq.ErrQueueNotFound) {
+					return err
+				}
+
+				total += n
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%d jobs deleted\n", total)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&queue, "queue", "q", "", "queue name, every queue when empty")
+	cmd.Flags().StringVarP(&state, "state", "s", stateArchived, "archived, completed, retry, pending or scheduled")
+
+	return cmd
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	return s[:n-3] + "..."
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mirusky-dev/challenge-18/core/env"
+	"github.com/mirusky-dev/challenge-18/core/mailer"
+)
+
+func newCmdMail() *cobra.Command {
+
+	cmd := &cobra.Command{
+		Use:   "mail",
+		Short: "Mail utilities",
+		Long:  "Utilities to inspect the emails sent by the system.",
+	}
+
+	cmd.AddCommand(
+		newCmdMailPreview(),
+	)
+
+	return cmd
+}
+
+func newCmdMailPreview() *cobra.Command {
+	var locale, format string
+
+	cmd := &cobra.Command{
+		Use:   "preview [template]",
+		Short: "Renders a mail template with sample data",
+		Long:  "Renders a mail template with sample data. Without a template name it lists the available templates and locales.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := env.Load()
+			if err != nil {
+				return err
+			}
+
+			templates, err := mailer.NewTemplateRenderer(config)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+
+			if len(args) == 0 {
+				fmt.Fprintln(out, "Templates:")
+				for _, name := range templates.Templates() {
+					fmt.Fprintln(out, "  "+name)
+				}
+				fmt.Fprintln(out, "Locales:")
+				for _, locale := range templates.Locales() {
+					fmt.Fprintln(out, "  "+locale)
+				}
+				return nil
+			}
+
+			content, err := templates.Preview(args[0], locale)
+			if err != nil {
+				return err
+			}
+
+			switch format {
+			case "text":
+				fmt.Fprintf(out, "Subject: %s\n\n%s", content.Subject, content.PlainText)
+			case "html":
+				fmt.Fprint(out, content.HTML)
+			default:
+				return fmt.Errorf("unknown format %q, expected html or text", format)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&locale, "locale", mailer.DefaultLocale, "locale used to render the template")
+	cmd.Flags().StringVar(&format, "format", "text", "output format: html or text")
+
+	return cmd
+}
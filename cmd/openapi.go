@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mirusky-dev/challenge-18/router"
+)
+
+func newCmdOpenAPI() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "openapi",
+		Short: "Writes the OpenAPI document of the API",
+		Long:  "Writes the OpenAPI 3.1 document of the API, e.g: to generate a client. It's the same document served at /api/openapi.json.",
+		Example: `  openapi > openapi.json
+  openapi --output docs/openapi.json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			if output != "" {
+				file, err := os.Create(output)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+
+				out = file
+			}
+
+			encoder := json.NewEncoder(out)
+			encoder.SetIndent("", "  ")
+
+			return encoder.Encode(router.OpenAPI())
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "file to write, stdout when empty")
+
+	return cmd
+}
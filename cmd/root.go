@@ -1,7 +1,14 @@
 package cmd
 
 import (
+	"log/slog"
+	"os"
+	"time"
+
 	"github.com/spf13/cobra"
+
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/core/env"
 )
 
 var (
@@ -14,6 +21,9 @@ Find more information at:
 	https://www.golangboilerplate.com`
 )
 
+// shutdownTimeout bounds the whole shutdown, each resource has at most the timeout of the lifecycle
+const shutdownTimeout = 30 * time.Second
+
 func NewCmdRoot() *cobra.Command {
 
 	cmd := &cobra.Command{
@@ -24,7 +34,21 @@ func NewCmdRoot() *cobra.Command {
 	cmd.AddCommand(
 		newCmdAPI(),
 		newCmdBackground(),
+		newCmdMail(),
+		newCmdOpenAPI(),
 	)
 
 	return cmd
 }
+
+// setupLogger makes the configured logger the default, the log package output goes through it too
+func setupLogger(config env.Config) error {
+	logger, err := core.NewLogger(os.Stderr, config.LogLevel, config.LogFormat)
+	if err != nil {
+		return err
+	}
+
+	slog.SetDefault(logger)
+
+	return nil
+}
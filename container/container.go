@@ -0,0 +1,160 @@
+// Package container is the composition root of the API, the background worker and the commands.
+//
+// Each dependency is built once, on its first Get, and the resources it opens are added to the lifecycle and to the
+// health checks. A test overrides a dependency by setting it before it's used, e.g:
+//
+//	c := container.New(config, "api", lc)
+//	c.Mailer.Set(mailer.NewMemoryMailer())
+//	app := router.Setup(c)
+package container
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/core/background"
+	"github.com/mirusky-dev/challenge-18/core/env"
+	"github.com/mirusky-dev/challenge-18/core/health"
+	"github.com/mirusky-dev/challenge-18/core/lifecycle"
+	"github.com/mirusky-dev/challenge-18/core/mailer"
+	"github.com/mirusky-dev/challenge-18/core/stream"
+	"github.com/mirusky-dev/challenge-18/core/webhook"
+	"github.com/mirusky-dev/challenge-18/repositories"
+	"github.com/mirusky-dev/challenge-18/services"
+)
+
+// Provider builds a dependency on the first Get
+type Provider[T any] struct {
+	name  string
+	build func() (T, error)
+
+	mu    sync.Mutex
+	value T
+	done  bool
+}
+
+func provide[T any](name string, build func() (T, error)) *Provider[T] {
+	return &Provider[T]{name: name, build: build}
+}
+
+// Get returns the dependency, building it on the first call. It panics when the build fails, see Resolve.
+func (p *Provider[T]) Get() T {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.done {
+		value, err := p.build()
+		if err != nil {
+			panic(buildError{fmt.Errorf("building %s: %w", p.name, err)})
+		}
+
+		p.value = value
+		p.done = true
+	}
+
+	return p.value
+}
+
+// Set overrides the dependency, it must be called before the first Get
+func (p *Provider[T]) Set(value T) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.value = value
+	p.done = true
+}
+
+type buildError struct {
+	err error
+}
+
+// Resolve calls fn, returning the error of a dependency that couldn't be built instead of panicking
+func Resolve[T any](fn func() T) (value T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			failed, ok := r.(buildError)
+			if !ok {
+				panic(r)
+			}
+
+			err = failed.err
+		}
+	}()
+
+	return fn(), nil
+}
+
+// Container holds the dependencies of a process
+type Container struct {
+	Config    env.Config
+	Lifecycle *lifecycle.Lifecycle
+
+	// Health checks the resources opened by the process
+	Health *health.Checker
+
+	// Resources
+	DB                       *Provider[*gorm.DB]
+	SQLDB                    *Provider[*sql.DB]
+	BackgroundClient         *Provider[*asynq.Client]
+	Inspector                *Provider[*asynq.Inspector]
+	Bus                      *Provider[*background.AsynqBus]
+	Publisher                *Provider[background.Publisher]
+	Broker                   *Provider[stream.Broker]
+	IdempotencyStore         *Provider[background.IdempotencyStore]
+	RefreshTokenStorage      *Provider[fiber.Storage]
+	TokenRevocationStorage   *Provider[fiber.Storage]
+	EmailVerificationStorage *Provider[fiber.Storage]
+	PasswordResetStorage     *Provider[fiber.Storage]
+
+	// Mailer is used by the services, it queues the mails for the worker. MailDriver is what the worker delivers them
+	// with, selected by config.Mailer.
+	Mailer     *Provider[mailer.Mailer]
+	MailDriver *Provider[mailer.Mailer]
+	Templates  *Provider[mailer.TemplateRenderer]
+
+	PasswordHasher *Provider[core.PasswordHasher]
+	WebhookSender  *Provider[webhook.Sender]
+
+	// Clock and IDs are what the services and the repositories tell the time and generate the IDs with
+	Clock *Provider[core.Clock]
+	IDs   *Provider[core.IDGenerator]
+
+	// Repositories, the ones called with the context of a unit of work share its transaction
+	UnitOfWork             *Provider[core.UnitOfWork]
+	UserRepository         *Provider[repositories.IUserRepository]
+	TaskRepository         *Provider[repositories.ITaskRepository]
+	WebhookRepository      *Provider[repositories.IWebhookRepository]
+	NotificationRepository *Provider[repositories.INotificationRepository]
+
+	// Services
+	UserService         *Provider[services.IUserService]
+	TaskService         *Provider[services.ITaskService]
+	AuthService         *Provider[services.IAuthService]
+	AccountService      *Provider[services.IAccountService]
+	TokenService        *Provider[services.ITokenService]
+	MailService         *Provider[services.IMailService]
+	WebhookService      *Provider[services.IWebhookService]
+	NotificationService *Provider[services.INotificationService]
+}
+
+// New creates the container of a process, the service names its health checks
+func New(config env.Config, service string, lc *lifecycle.Lifecycle) *Container {
+	c := &Container{
+		Config:    config,
+		Lifecycle: lc,
+		Health:    health.NewChecker(service, 2*time.Second, 5*time.Second),
+	}
+
+	c.provideResources()
+	c.provideRepositories()
+	c.provideServices()
+
+	return c
+}
@@ -0,0 +1,60 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/core/background"
+	"github.com/mirusky-dev/challenge-18/core/env"
+	"github.com/mirusky-dev/challenge-18/core/lifecycle"
+)
+
+func newContainer() *Container {
+	lc := lifecycle.New(slog.New(slog.NewTextHandler(io.Discard, nil)), time.Second)
+	return New(env.Config{}, "test", lc)
+}
+
+func TestProvider(t *testing.T) {
+	builds := 0
+	p := provide("counter", func() (int, error) {
+		builds++
+		return 42, nil
+	})
+
+	assert.Equal(t, 42, p.Get())
+	assert.Equal(t, 42, p.Get())
+	assert.Equal(t, 1, builds, "built once")
+
+	failing := provide("broken", func() (int, error) { return 0, errors.New("connection refused") })
+	_, err := Resolve(failing.Get)
+	assert.EqualError(t, err, "building broken: connection refused")
+
+	assert.Panics(t, func() { _, _ = Resolve(func() int { panic("not a build error") }) })
+}
+
+func TestOverride(t *testing.T) {
+	c := newContainer()
+
+	// The asynq client can't be built without REDIS_URL
+	_, err := Resolve(c.Publisher.Get)
+	assert.ErrorContains(t, err, "building asynq-client")
+
+	c = newContainer()
+	c.Publisher.Set(background.NewMemoryBus(core.NewSystemClock(), core.NewUUIDv7Generator()))
+	c.UserRepository.Set(nil) // not called
+
+	service, err := Resolve(c.UserService.Get)
+	require.NoError(t, err)
+	assert.NotNil(t, service)
+
+	report := c.Health.Check(context.Background())
+	assert.Empty(t, report.Checks, "the overrides don't open any resource")
+}
@@ -0,0 +1,184 @@
+package container
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/storage/redis"
+	"github.com/hibiken/asynq"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/core/background"
+	"github.com/mirusky-dev/challenge-18/core/health"
+	"github.com/mirusky-dev/challenge-18/core/lifecycle"
+	"github.com/mirusky-dev/challenge-18/core/mailer"
+	"github.com/mirusky-dev/challenge-18/core/metrics"
+	"github.com/mirusky-dev/challenge-18/core/stream"
+	"github.com/mirusky-dev/challenge-18/core/tracing"
+	"github.com/mirusky-dev/challenge-18/core/webhook"
+	"github.com/mirusky-dev/challenge-18/repositories"
+)
+
+// Redis databases of the storages, asynq uses the 12
+const (
+	redisRefreshToken      = 1
+	redisTokenRevocation   = 2
+	redisEmailVerification = 3
+	redisPasswordReset     = 4
+	redisIdempotency       = 6
+)
+
+func (c *Container) provideResources() {
+	c.DB = provide("mysql", func() (*gorm.DB, error) {
+		db, err := gorm.Open(mysql.Open(c.Config.DatabaseURL), &gorm.Config{NowFunc: c.now, TranslateError: true})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := db.Use(repositories.NewIDPlugin(c.IDs.Get())); err != nil {
+			return nil, err
+		}
+
+		if err := db.Use(metrics.NewGormPlugin()); err != nil {
+			return nil, err
+		}
+
+		if err := db.Use(tracing.NewGormPlugin()); err != nil {
+			return nil, err
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+
+		c.Lifecycle.AppendCloser("mysql", sqlDB)
+		c.Health.Add("mysql", health.SQL(sqlDB))
+
+		return db, nil
+	})
+
+	c.SQLDB = provide("sql", func() (*sql.DB, error) {
+		return c.DB.Get().DB()
+	})
+
+	c.BackgroundClient = provide("asynq-client", func() (*asynq.Client, error) {
+		client, err := background.NewClient(c.Config)
+		if err != nil {
+			return nil, err
+		}
+
+		c.Lifecycle.AppendCloser("asynq-client", client)
+		return client, nil
+	})
+
+	c.Inspector = provide("asynq-inspector", func() (*asynq.Inspector, error) {
+		inspector, err := background.NewInspector(c.Config)
+		if err != nil {
+			return nil, err
+		}
+
+		c.Lifecycle.AppendCloser("asynq-inspector", inspector)
+		c.Health.Add("asynq", health.Asynq(inspector))
+
+		return inspector, nil
+	})
+
+	// Domain events are handled by the subscribers registered in the background worker
+	c.Bus = provide("bus", func() (*background.AsynqBus, error) {
+		return background.NewAsynqBus(c.BackgroundClient.Get(), c.Clock.Get(), c.IDs.Get()), nil
+	})
+
+	c.Publisher = provide("publisher", func() (background.Publisher, error) {
+		return c.Bus.Get(), nil
+	})
+
+	// Pub/sub isn't bound to a database, every API replica shares the same channel
+	c.Broker = provide("stream-broker", func() (stream.Broker, error) {
+		client := redis.New(redis.Config{URL: c.Config.RedisURL}).Conn()
+		c.Lifecycle.AppendCloser("redis-stream", client)
+		c.Health.Add("redis-stream", health.Redis(client))
+
+		broker, err := stream.NewRedisBroker(context.Background(), client, stream.DefaultChannel, c.Clock.Get(), c.IDs.Get())
+		if err != nil {
+			return nil, err
+		}
+
+		c.Lifecycle.AppendCloser("stream-broker", broker)
+		return broker, nil
+	})
+
+	c.IdempotencyStore = provide("idempotency-store", func() (background.IdempotencyStore, error) {
+		client := redis.New(redis.Config{URL: c.redisURL(redisIdempotency)}).Conn()
+		c.Lifecycle.AppendCloser("redis-idempotency", client)
+		c.Health.Add("redis-idempotency", health.Redis(client))
+
+		return background.NewRedisIdempotencyStore(client, 7*24*time.Hour), nil
+	})
+
+	c.RefreshTokenStorage = c.provideStorage("refresh-token", redisRefreshToken)
+	c.TokenRevocationStorage = c.provideStorage("token-revocation", redisTokenRevocation)
+	c.EmailVerificationStorage = c.provideStorage("email-verification", redisEmailVerification)
+	c.PasswordResetStorage = c.provideStorage("password-reset", redisPasswordReset)
+
+	// Mails are delivered by the background worker, so a slow mail server doesn't hold the request
+	c.Mailer = provide("mailer", func() (mailer.Mailer, error) {
+		return background.NewQueuedMailer(c.BackgroundClient.Get(), c.IDs.Get()), nil
+	})
+
+	c.MailDriver = provide("mail-driver", func() (mailer.Mailer, error) {
+		m, err := mailer.New(c.Config, c.Clock.Get(), c.IDs.Get())
+		if err != nil {
+			return nil, err
+		}
+
+		c.Lifecycle.Append(lifecycle.Hook{Name: "mailer", OnStop: func(ctx context.Context) error { return mailer.Close(m) }})
+		return m, nil
+	})
+
+	c.Templates = provide("templates", func() (mailer.TemplateRenderer, error) {
+		return mailer.NewTemplateRenderer(c.Config)
+	})
+
+	c.PasswordHasher = provide("password-hasher", func() (core.PasswordHasher, error) {
+		return core.NewArgon2IDPasswordHasher(), nil
+	})
+
+	c.WebhookSender = provide("webhook-sender", func() (webhook.Sender, error) {
+		return webhook.NewSender(10 * time.Second), nil
+	})
+
+	c.Clock = provide("clock", func() (core.Clock, error) {
+		return core.NewSystemClock(), nil
+	})
+
+	c.IDs = provide("ids", func() (core.IDGenerator, error) {
+		return core.NewUUIDv7Generator(), nil
+	})
+}
+
+// now is the gorm NowFunc, the timestamps of the entities follow the clock. Like the gorm default it's in the local
+// time zone.
+func (c *Container) now() time.Time {
+	return c.Clock.Get().Now().Local()
+}
+
+// provideStorage provides a traced Redis storage, the name identifies it in the spans and the health checks
+func (c *Container) provideStorage(name string, database int) *Provider[fiber.Storage] {
+	return provide(name, func() (fiber.Storage, error) {
+		storage := redis.New(redis.Config{URL: c.redisURL(database)})
+		c.Lifecycle.AppendCloser("redis-"+name, storage)
+		c.Health.Add("redis-"+name, health.Redis(storage.Conn()))
+
+		return tracing.NewStorage(name, storage), nil
+	})
+}
+
+func (c *Container) redisURL(database int) string {
+	return c.Config.RedisURL + "/" + strconv.Itoa(database)
+}
@@ -0,0 +1,63 @@
+package container
+
+import (
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/repositories"
+	"github.com/mirusky-dev/challenge-18/services"
+)
+
+func (c *Container) provideRepositories() {
+	c.UnitOfWork = provide("unit-of-work", func() (core.UnitOfWork, error) {
+		return repositories.NewUnitOfWork(c.DB.Get()), nil
+	})
+
+	c.UserRepository = provide("user-repository", func() (repositories.IUserRepository, error) {
+		return repositories.NewUserRepository(c.DB.Get()), nil
+	})
+
+	c.TaskRepository = provide("task-repository", func() (repositories.ITaskRepository, error) {
+		return repositories.NewTaskRepository(c.DB.Get()), nil
+	})
+
+	c.WebhookRepository = provide("webhook-repository", func() (repositories.IWebhookRepository, error) {
+		return repositories.NewWebhookRepository(c.DB.Get()), nil
+	})
+
+	c.NotificationRepository = provide("notification-repository", func() (repositories.INotificationRepository, error) {
+		return repositories.NewNotificationRepository(c.DB.Get(), c.Clock.Get()), nil
+	})
+}
+
+func (c *Container) provideServices() {
+	c.UserService = provide("user-service", func() (services.IUserService, error) {
+		return services.NewUserService(c.UserRepository.Get(), c.PasswordHasher.Get(), c.Publisher.Get()), nil
+	})
+
+	c.TaskService = provide("task-service", func() (services.ITaskService, error) {
+		return services.NewTaskService(c.Clock.Get(), c.UnitOfWork.Get(), c.TaskRepository.Get(), c.Publisher.Get(), c.Broker.Get()), nil
+	})
+
+	c.TokenService = provide("token-service", func() (services.ITokenService, error) {
+		return services.NewTokenService(c.Config, c.Clock.Get(), c.IDs.Get(), c.RefreshTokenStorage.Get(), c.TokenRevocationStorage.Get(), c.UserRepository.Get()), nil
+	})
+
+	c.MailService = provide("mail-service", func() (services.IMailService, error) {
+		return services.NewMailService(c.Inspector.Get()), nil
+	})
+
+	c.WebhookService = provide("webhook-service", func() (services.IWebhookService, error) {
+		return services.NewWebhookService(c.WebhookRepository.Get(), c.BackgroundClient.Get()), nil
+	})
+
+	c.NotificationService = provide("notification-service", func() (services.INotificationService, error) {
+		return services.NewNotificationService(c.NotificationRepository.Get()), nil
+	})
+
+	c.AccountService = provide("account-service", func() (services.IAccountService, error) {
+		return services.NewAccountService(c.Config, c.Mailer.Get(), c.Templates.Get(), c.PasswordHasher.Get(), c.EmailVerificationStorage.Get(), c.UnitOfWork.Get(), c.UserRepository.Get()), nil
+	})
+
+	c.AuthService = provide("auth-service", func() (services.IAuthService, error) {
+		return services.NewAuthService(c.Config, c.Mailer.Get(), c.Templates.Get(), c.PasswordHasher.Get(), c.UnitOfWork.Get(), c.UserRepository.Get(), c.PasswordResetStorage.Get(), c.TokenService.Get()), nil
+	})
+}
@@ -0,0 +1,197 @@
+package background
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/core/tracing"
+)
+
+var (
+	ErrUnsupportedVersion = errors.New("unsupported event version")
+	ErrSubscriberNotFound = errors.New("event subscriber not found")
+)
+
+// Event is a domain event, the struct itself is the payload
+type Event interface {
+	// EventType is the name used to route the event, e.g: task.completed
+	EventType() string
+	// EventVersion is the payload schema version, it must be increased on breaking changes
+	EventVersion() int
+}
+
+// Envelope wraps the payload with the data every event has
+type Envelope struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	Version    int       `json:"version"`
+	OccurredAt time.Time `json:"occurredAt"`
+	Actor      string    `json:"actor,omitempty"` // who caused the event, empty when it was the system
+	// CorrelationID and TraceContext join the logs and the spans of the handlers to the request that published the event
+	CorrelationID string            `json:"correlationId,omitempty"`
+	TraceContext  map[string]string `json:"traceContext,omitempty"`
+	Payload       json.RawMessage   `json:"payload"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+}
+
+// Decode unmarshals the payload into v
+func (e Envelope) Decode(v any) error {
+	return json.Unmarshal(e.Payload, v)
+}
+
+// NewEnvelope wraps the event, the actor, the correlation ID and the trace context are taken from ctx when available.
+// The ID and the time come from ids and clock
+func NewEnvelope(ctx context.Context, clock core.Clock, ids core.IDGenerator, event Event) (Envelope, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	envelope := Envelope{
+		ID:            ids.NewID(),
+		Type:          event.EventType(),
+		Version:       event.EventVersion(),
+		OccurredAt:    clock.Now().UTC(),
+		CorrelationID: core.CorrelationID(ctx),
+		TraceContext:  tracing.Inject(ctx),
+		Payload:       payload,
+	}
+
+	if appCtx, ok := core.FromContext(ctx); ok {
+		envelope.Actor = appCtx.UserID()
+	}
+
+	return envelope, nil
+}
+
+// Handler reacts to an event, returning an error makes it retry when the implementation supports it
+type Handler func(ctx context.Context, e Envelope) error
+
+// Upcaster converts a payload from a version to the next one
+type Upcaster func(payload json.RawMessage) (json.RawMessage, error)
+
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+type Subscriber interface {
+	// Subscribe adds a handler for the event type, the name identifies it among the others of the same type
+	Subscribe(eventType, name string, handler Handler)
+
+	// Upcast registers how to convert the payloads of fromVersion to fromVersion+1, so old events reach the
+	// subscribers in the latest version
+	Upcast(eventType string, fromVersion int, upcaster Upcaster)
+}
+
+type Bus interface {
+	Publisher
+	Subscriber
+}
+
+// On subscribes a typed handler, the payload is decoded into T after being upcasted
+func On[T Event](s Subscriber, name string, handler func(ctx context.Context, e Envelope, event T) error) {
+	var zero T
+
+	s.Subscribe(zero.EventType(), name, func(ctx context.Context, e Envelope) error {
+		if e.Version > zero.EventVersion() {
+			return fmt.Errorf("%w: %s v%d, %s handles up to v%d", ErrUnsupportedVersion, e.Type, e.Version, name, zero.EventVersion())
+		}
+
+		var event T
+		if err := e.Decode(&event); err != nil {
+			return err
+		}
+
+		return handler(ctx, e, event)
+	})
+}
+
+type subscription struct {
+	name    string
+	handler Handler
+}
+
+// registry keeps the subscriptions and upcasters, it's shared by the bus implementations
+type registry struct {
+	mu            sync.RWMutex
+	subscriptions map[string][]subscription
+	upcasters     map[string]map[int]Upcaster
+}
+
+func newRegistry() *registry {
+	return &registry{
+		subscriptions: map[string][]subscription{},
+		upcasters:     map[string]map[int]Upcaster{},
+	}
+}
+
+func (r *registry) Subscribe(eventType, name string, handler Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range r.subscriptions[eventType] {
+		if s.name == name {
+			panic(fmt.Sprintf("background: subscriber %q already registered for %s", name, eventType))
+		}
+	}
+
+	r.subscriptions[eventType] = append(r.subscriptions[eventType], subscription{name: name, handler: handler})
+}
+
+func (r *registry) Upcast(eventType string, fromVersion int, upcaster Upcaster) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.upcasters[eventType] == nil {
+		r.upcasters[eventType] = map[int]Upcaster{}
+	}
+
+	r.upcasters[eventType][fromVersion] = upcaster
+}
+
+// subscribers returns the name of the subscribers of the event type
+func (r *registry) subscribers(eventType string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.subscriptions[eventType]))
+	for _, s := range r.subscriptions[eventType] {
+		names = append(names, s.name)
+	}
+
+	return names
+}
+
+// deliver upcasts the envelope and calls the named subscriber
+func (r *registry) deliver(ctx context.Context, name string, e Envelope) error {
+	r.mu.RLock()
+	var handler Handler
+	for _, s := range r.subscriptions[e.Type] {
+		if s.name == name {
+			handler = s.handler
+		}
+	}
+	upcasters := r.upcasters[e.Type]
+	r.mu.RUnlock()
+
+	if handler == nil {
+		return fmt.Errorf("%w: %s for %s", ErrSubscriberNotFound, name, e.Type)
+	}
+
+	for upcaster, ok := upcasters[e.Version]; ok; upcaster, ok = upcasters[e.Version] {
+		payload, err := upcaster(e.Payload)
+		if err != nil {
+			return fmt.Errorf("upcasting %s v%d: %w", e.Type, e.Version, err)
+		}
+
+		e.Payload = payload
+		e.Version++
+	}
+
+	return handler(ctx, e)
+}
@@ -0,0 +1,122 @@
+package background
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/core/background/events"
+)
+
+const (
+	typeBusEvent   = "bus.event"
+	typeBusDeliver = "bus.deliver"
+
+	// QueueEvents is the queue used by the bus tasks
+	QueueEvents = "events"
+)
+
+type busDeliverPayload struct {
+	Subscriber string
+	Envelope   Envelope
+
+	events.Metadata
+}
+
+// AsynqBus publishes the events as asynq tasks.
+//
+// A published event is a single bus.event task, the worker fans it out to one bus.deliver task per subscriber,
+// so each subscriber is retried on its own.
+type AsynqBus struct {
+	*registry
+
+	client *asynq.Client
+	clock  core.Clock
+	ids    core.IDGenerator
+}
+
+func NewAsynqBus(client *asynq.Client, clock core.Clock, ids core.IDGenerator) *AsynqBus {
+	return &AsynqBus{
+		registry: newRegistry(),
+		client:   client,
+		clock:    clock,
+		ids:      ids,
+	}
+}
+
+func (b *AsynqBus) Publish(ctx context.Context, event Event) error {
+	envelope, err := NewEnvelope(ctx, b.clock, b.ids, event)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	task := asynq.NewTask(typeBusEvent, payload, asynq.Queue(QueueEvents), asynq.TaskID(envelope.ID), asynq.MaxRetry(10))
+
+	_, err = Enqueue(ctx, b.client, task)
+	return err
+}
+
+// Register adds the bus handlers to the worker mux, the subscriptions must be done on this same bus
+func (b *AsynqBus) Register(mux *asynq.ServeMux) {
+	mux.HandleFunc(typeBusEvent, b.handleEvent)
+	mux.HandleFunc(typeBusDeliver, b.handleDeliver)
+}
+
+func (b *AsynqBus) handleEvent(ctx context.Context, t *asynq.Task) error {
+	var envelope Envelope
+	if err := json.Unmarshal(t.Payload(), &envelope); err != nil {
+		return fmt.Errorf("json.Unmarshal failed: %v: %w", err, asynq.SkipRetry)
+	}
+
+	for _, name := range b.subscribers(envelope.Type) {
+		payload, err := json.Marshal(busDeliverPayload{
+			Subscriber: name,
+			Envelope:   envelope,
+			Metadata:   events.NewMetadata(ctx),
+		})
+		if err != nil {
+			return err
+		}
+
+		task := asynq.NewTask(
+			typeBusDeliver,
+			payload,
+			asynq.Queue(QueueEvents),
+			// A retry of the fan-out doesn't deliver twice to the subscribers already enqueued
+			asynq.TaskID(envelope.ID+":"+name),
+			asynq.MaxRetry(10),
+			asynq.Timeout(5*time.Minute),
+		)
+
+		if _, err := Enqueue(ctx, b.client, task); err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *AsynqBus) handleDeliver(ctx context.Context, t *asynq.Task) error {
+	var p busDeliverPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("json.Unmarshal failed: %v: %w", err, asynq.SkipRetry)
+	}
+
+	err := b.deliver(ctx, p.Subscriber, p.Envelope)
+	if errors.Is(err, ErrSubscriberNotFound) || errors.Is(err, ErrUnsupportedVersion) {
+		// Retrying won't help until a worker with the subscriber is deployed, it stays archived to be retried
+		return fmt.Errorf("%v: %w", err, asynq.SkipRetry)
+	}
+
+	return err
+}
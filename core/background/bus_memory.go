@@ -0,0 +1,64 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/mirusky-dev/challenge-18/core"
+)
+
+// MemoryBus delivers the events synchronously in the current process, meant for tests and tools without Redis
+type MemoryBus struct {
+	*registry
+
+	mu        sync.Mutex
+	published []Envelope
+	clock     core.Clock
+	ids       core.IDGenerator
+}
+
+func NewMemoryBus(clock core.Clock, ids core.IDGenerator) *MemoryBus {
+	return &MemoryBus{
+		registry: newRegistry(),
+		clock:    clock,
+		ids:      ids,
+	}
+}
+
+// Publish calls every subscriber of the event and returns all their errors joined
+func (b *MemoryBus) Publish(ctx context.Context, event Event) error {
+	envelope, err := NewEnvelope(ctx, b.clock, b.ids, event)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	b.published = append(b.published, envelope)
+	b.mu.Unlock()
+
+	var errs []error
+	for _, name := range b.subscribers(envelope.Type) {
+		if err := b.deliver(ctx, name, envelope); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Published returns a copy of every published envelope, in order
+func (b *MemoryBus) Published() []Envelope {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]Envelope(nil), b.published...)
+}
+
+// Reset forgets the published envelopes
+func (b *MemoryBus) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.published = nil
+}
@@ -0,0 +1,102 @@
+package background
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirusky-dev/challenge-18/core"
+)
+
+type greeted struct {
+	Name string `json:"name"`
+}
+
+func (greeted) EventType() string { return "test.greeted" }
+func (greeted) EventVersion() int { return 2 }
+
+// greetedV1 is how test.greeted looked before the version 2
+type greetedV1 struct {
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+}
+
+func (greetedV1) EventType() string { return "test.greeted" }
+func (greetedV1) EventVersion() int { return 1 }
+
+func TestMemoryBus_Publish(t *testing.T) {
+	bus := NewMemoryBus(core.NewSystemClock(), core.NewUUIDv7Generator())
+
+	var got []string
+	On(bus, "first", func(ctx context.Context, e Envelope, event greeted) error {
+		got = append(got, "first:"+event.Name)
+		return nil
+	})
+	On(bus, "second", func(ctx context.Context, e Envelope, event greeted) error {
+		got = append(got, "second:"+event.Name)
+		return errors.New("boom")
+	})
+
+	appCtx := core.NewUserCtx("user-1", []string{"tech"}, nil)
+	err := bus.Publish(core.NewContext(context.Background(), appCtx), greeted{Name: "jane"})
+
+	assert.ErrorContains(t, err, "boom", "the subscribers errors should be returned")
+	assert.Equal(t, []string{"first:jane", "second:jane"}, got)
+
+	published := bus.Published()
+	require.Len(t, published, 1)
+	assert.Equal(t, "test.greeted", published[0].Type)
+	assert.Equal(t, 2, published[0].Version)
+	assert.Equal(t, "user-1", published[0].Actor)
+	assert.Equal(t, appCtx.ID(), published[0].CorrelationID)
+	assert.NotEmpty(t, published[0].ID)
+
+	bus.Reset()
+	assert.Empty(t, bus.Published())
+}
+
+func TestMemoryBus_Upcast(t *testing.T) {
+	bus := NewMemoryBus(core.NewSystemClock(), core.NewUUIDv7Generator())
+
+	bus.Upcast("test.greeted", 1, func(payload json.RawMessage) (json.RawMessage, error) {
+		var v1 greetedV1
+		if err := json.Unmarshal(payload, &v1); err != nil {
+			return nil, err
+		}
+		return json.Marshal(greeted{Name: v1.FirstName + " " + v1.LastName})
+	})
+
+	var got greeted
+	On(bus, "upcasted", func(ctx context.Context, e Envelope, event greeted) error {
+		assert.Equal(t, 2, e.Version)
+		got = event
+		return nil
+	})
+
+	require.NoError(t, bus.Publish(context.Background(), greetedV1{FirstName: "Jane", LastName: "Doe"}))
+	assert.Equal(t, "Jane Doe", got.Name)
+}
+
+func TestMemoryBus_UnsupportedVersion(t *testing.T) {
+	bus := NewMemoryBus(core.NewSystemClock(), core.NewUUIDv7Generator())
+
+	On(bus, "old", func(ctx context.Context, e Envelope, event greetedV1) error {
+		t.Fatal("a newer payload shouldn't reach an older handler")
+		return nil
+	})
+
+	err := bus.Publish(context.Background(), greeted{Name: "jane"})
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+}
+
+func TestMemoryBus_DuplicatedSubscriber(t *testing.T) {
+	bus := NewMemoryBus(core.NewSystemClock(), core.NewUUIDv7Generator())
+	handler := func(ctx context.Context, e Envelope) error { return nil }
+
+	bus.Subscribe("test.greeted", "same", handler)
+	assert.Panics(t, func() { bus.Subscribe("test.greeted", "same", handler) })
+}
@@ -1,16 +1,60 @@
 package background
 
 import (
+	"context"
+
 	"github.com/hibiken/asynq"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/mirusky-dev/challenge-18/core/env"
+	"github.com/mirusky-dev/challenge-18/core/tracing"
 )
 
+func redisConnOpt(config env.Config) (asynq.RedisConnOpt, error) {
+	return asynq.ParseRedisURI(config.RedisURL + "/12")
+}
+
 func NewClient(config env.Config) (*asynq.Client, error) {
 
-	redisOpt, err := asynq.ParseRedisURI(config.RedisURL + "/12")
+	redisOpt, err := redisConnOpt(config)
 	if err != nil {
 		return nil, err
 	}
 
 	return asynq.NewClient(redisOpt), nil
 }
+
+// NewInspector creates an inspector to query and manage the queues, e.g: list the archived (dead) tasks
+func NewInspector(config env.Config) (*asynq.Inspector, error) {
+
+	redisOpt, err := redisConnOpt(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return asynq.NewInspector(redisOpt), nil
+}
+
+// Enqueue enqueues the task within a producer span, the task payload carries the trace context with its
+// events.Metadata so the worker continues the trace
+func Enqueue(ctx context.Context, client *asynq.Client, task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	ctx, span := tracing.Start(ctx, "enqueue "+task.Type(),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "asynq"),
+			attribute.String("messaging.operation", "publish"),
+		),
+	)
+
+	info, err := client.EnqueueContext(ctx, task, opts...)
+	if err == nil {
+		span.SetAttributes(
+			attribute.String("messaging.message.id", info.ID),
+			attribute.String("messaging.destination.name", info.Queue),
+		)
+	}
+
+	tracing.End(span, err)
+	return info, err
+}
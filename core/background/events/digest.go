@@ -0,0 +1,63 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+const (
+	TypeDigestRun  string = "digest.run"
+	TypeDigestSend string = "digest.send"
+)
+
+// DigestRunPayload is scheduled periodically, it enqueues a digest.send for each manager with the frequency
+type DigestRunPayload struct {
+	Frequency string
+}
+
+func NewDigestRun(frequency string) (*asynq.Task, error) {
+	payload, err := json.Marshal(DigestRunPayload{
+		Frequency: frequency,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return asynq.NewTask(TypeDigestRun, payload, asynq.MaxRetry(3)), nil
+}
+
+type DigestSendPayload struct {
+	ManagerID string
+	Frequency string
+	From      time.Time
+	To        time.Time
+
+	Metadata
+}
+
+// NewDigestSend creates the task that aggregates and mails the digest of a manager.
+//
+// The task ID is derived from the period, so running the scheduler in more than one worker doesn't send it twice.
+func NewDigestSend(ctx context.Context, managerID, frequency string, from, to time.Time) (*asynq.Task, error) {
+	payload, err := json.Marshal(DigestSendPayload{
+		ManagerID: managerID,
+		Frequency: frequency,
+		From:      from,
+		To:        to,
+		Metadata:  NewMetadata(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return asynq.NewTask(
+		TypeDigestSend,
+		payload,
+		asynq.TaskID(TypeDigestSend+":"+managerID+":"+frequency+":"+to.Format(time.RFC3339)),
+		asynq.MaxRetry(5),
+		asynq.Retention(24*time.Hour),
+	), nil
+}
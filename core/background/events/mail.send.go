@@ -0,0 +1,49 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/mirusky-dev/challenge-18/core/mailer"
+)
+
+const (
+	TypeMailSend string = "mail.send"
+
+	// QueueMail keeps mails apart from other jobs, so the dead letters are easy to find
+	QueueMail string = "mail"
+)
+
+type MailSendPayload struct {
+	// ID is the task ID, the worker sends each one once
+	ID   string
+	Mail mailer.Mail
+
+	Metadata
+}
+
+// NewMailSend creates the task that sends the mail, id becomes the task ID
+func NewMailSend(ctx context.Context, id string, mail mailer.Mail) (*asynq.Task, error) {
+	payload, err := json.Marshal(MailSendPayload{
+		ID:       id,
+		Mail:     mail,
+		Metadata: NewMetadata(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return asynq.NewTask(
+		TypeMailSend,
+		payload,
+		asynq.Queue(QueueMail),
+		// Two identical mails are two tasks, only a redelivery of the same task is skipped
+		asynq.TaskID(id),
+		asynq.MaxRetry(10),
+		asynq.Timeout(time.Minute),
+		asynq.Retention(24*time.Hour),
+	), nil
+}
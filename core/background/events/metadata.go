@@ -0,0 +1,22 @@
+package events
+
+import (
+	"context"
+
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/core/tracing"
+)
+
+// Metadata is embedded by the task payloads, it joins the task to the request that enqueued it: the logs by the
+// correlation ID and the spans by the trace context
+type Metadata struct {
+	CorrelationID string
+	TraceContext  map[string]string `json:",omitempty"`
+}
+
+func NewMetadata(ctx context.Context) Metadata {
+	return Metadata{
+		CorrelationID: core.CorrelationID(ctx),
+		TraceContext:  tracing.Inject(ctx),
+	}
+}
@@ -0,0 +1,44 @@
+package events
+
+import (
+	"time"
+)
+
+const (
+	TypeTaskCreated   string = "task.created"
+	TypeTaskUpdated   string = "task.updated"
+	TypeTaskCompleted string = "task.completed"
+	TypeTaskDeleted   string = "task.deleted"
+)
+
+// Task is the state of the task when the event happened
+type Task struct {
+	ID          string     `json:"id"`
+	Summary     string     `json:"summary"`
+	UserID      string     `json:"userId"`
+	PerformedAt *time.Time `json:"performedAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+type TaskCreated struct {
+	Task
+}
+
+func (TaskCreated) EventType() string { return TypeTaskCreated }
+func (TaskCreated) EventVersion() int { return 1 }
+
+type TaskCompleted struct {
+	Task
+}
+
+func (TaskCompleted) EventType() string { return TypeTaskCompleted }
+func (TaskCompleted) EventVersion() int { return 1 }
+
+// TaskDeleted carries the last state since the task can't be read after it's deleted
+type TaskDeleted struct {
+	Task
+}
+
+func (TaskDeleted) EventType() string { return TypeTaskDeleted }
+func (TaskDeleted) EventVersion() int { return 1 }
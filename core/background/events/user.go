@@ -0,0 +1,37 @@
+package events
+
+import (
+	"time"
+)
+
+const (
+	TypeUserCreated string = "user.created"
+	TypeUserDeleted string = "user.deleted"
+)
+
+// User is the state of the user when the event happened, without the credentials
+type User struct {
+	ID              string    `json:"id"`
+	Username        string    `json:"username"`
+	Email           string    `json:"email"`
+	IsEmailVerified bool      `json:"isEmailVerified"`
+	Role            string    `json:"role"`
+	Locale          string    `json:"locale"`
+	ManagerID       *string   `json:"managerId"`
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+type UserCreated struct {
+	User
+}
+
+func (UserCreated) EventType() string { return TypeUserCreated }
+func (UserCreated) EventVersion() int { return 1 }
+
+type UserDeleted struct {
+	User
+}
+
+func (UserDeleted) EventType() string { return TypeUserDeleted }
+func (UserDeleted) EventVersion() int { return 1 }
@@ -0,0 +1,57 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+const (
+	TypeWebhookDeliver string = "webhook.deliver"
+
+	QueueWebhook string = "webhook"
+)
+
+// WebhookEvents are the event types a webhook can subscribe to
+var WebhookEvents = []string{
+	TypeTaskCreated,
+	TypeTaskCompleted,
+	TypeTaskDeleted,
+	TypeUserCreated,
+	TypeUserDeleted,
+}
+
+// WebhookBody is the body sent to the webhook receivers, it's stored in the delivery so redeliveries send the same
+type WebhookBody struct {
+	ID         string          `json:"id"`
+	Event      string          `json:"event"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Data       json.RawMessage `json:"data"`
+}
+
+type WebhookDeliverPayload struct {
+	DeliveryID string
+
+	Metadata
+}
+
+func NewWebhookDeliver(ctx context.Context, deliveryID string) (*asynq.Task, error) {
+	payload, err := json.Marshal(WebhookDeliverPayload{
+		DeliveryID: deliveryID,
+		Metadata:   NewMetadata(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return asynq.NewTask(
+		TypeWebhookDeliver,
+		payload,
+		asynq.Queue(QueueWebhook),
+		asynq.TaskID(deliveryID),
+		asynq.MaxRetry(8),
+		asynq.Timeout(time.Minute),
+	), nil
+}
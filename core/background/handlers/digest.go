@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/mirusky-dev/challenge-18/core/background"
+	"github.com/mirusky-dev/challenge-18/core/background/events"
+	"github.com/mirusky-dev/challenge-18/core/mailer"
+	"github.com/mirusky-dev/challenge-18/models/entities"
+	"github.com/mirusky-dev/challenge-18/repositories"
+)
+
+// digestPeriods is how far back each digest frequency looks
+var digestPeriods = map[string]time.Duration{
+	entities.DigestDaily:  24 * time.Hour,
+	entities.DigestWeekly: 7 * 24 * time.Hour,
+}
+
+// HandleDigestRun enqueues the digest of every manager that chose the frequency
+func (ctrl *Controller) HandleDigestRun(ctx context.Context, t *asynq.Task) error {
+	var p events.DigestRunPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("json.Unmarshal failed: %v: %w", err, asynq.SkipRetry)
+	}
+
+	period, ok := digestPeriods[p.Frequency]
+	if !ok {
+		return fmt.Errorf("unknown digest frequency %q: %w", p.Frequency, asynq.SkipRetry)
+	}
+
+	managers, exception := ctrl.UserRepository.FindByDigestFrequency(ctx, p.Frequency)
+	if exception != nil {
+		return exception
+	}
+
+	// Truncating makes the period the same for every worker that runs the schedule
+	to := ctrl.Clock.Now().UTC().Truncate(time.Hour)
+	from := to.Add(-period)
+
+	for _, manager := range managers {
+		task, err := events.NewDigestSend(ctx, manager.ID, p.Frequency, from, to)
+		if err != nil {
+			return err
+		}
+
+		if _, err := background.Enqueue(ctx, ctrl.BackgroundClient, task); err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// HandleDigestSend aggregates the tasks of the manager's techs over the period and mails a summary
+func (ctrl *Controller) HandleDigestSend(ctx context.Context, t *asynq.Task) error {
+	var p events.DigestSendPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("json.Unmarshal failed: %v: %w", err, asynq.SkipRetry)
+	}
+
+	manager, exception := ctrl.UserRepository.GetByID(ctx, p.ManagerID)
+	if exception != nil {
+		return exception
+	}
+
+	techs, exception := ctrl.UserRepository.FindByManagerID(ctx, manager.ID)
+	if exception != nil {
+		return exception
+	}
+
+	techNames := map[string]string{}
+	techIDs := []string{}
+	for _, tech := range techs {
+		techNames[tech.ID] = tech.Username
+		techIDs = append(techIDs, tech.ID)
+	}
+
+	completed, exception := ctrl.TaskRepository.Find(ctx, repositories.TaskFilter{
+		UserIDs:       techIDs,
+		PerformedFrom: p.From,
+		PerformedTo:   p.To,
+	})
+	if exception != nil {
+		return exception
+	}
+
+	created, exception := ctrl.TaskRepository.Find(ctx, repositories.TaskFilter{
+		UserIDs:     techIDs,
+		CreatedFrom: p.From,
+		CreatedTo:   p.To,
+	})
+	if exception != nil {
+		return exception
+	}
+
+	overdue, exception := ctrl.TaskRepository.Find(ctx, repositories.TaskFilter{
+		UserIDs:   techIDs,
+		CreatedTo: p.To.Add(-ctrl.Config.DigestOverdueAfter),
+		OnlyOpen:  true,
+	})
+	if exception != nil {
+		return exception
+	}
+
+	// There is nothing worth an email
+	if len(completed) == 0 && len(created) == 0 && len(overdue) == 0 {
+		return nil
+	}
+
+	digestTasks := func(tasks []entities.Task, date func(entities.Task) time.Time) []mailer.DigestTask {
+		items := make([]mailer.DigestTask, 0, len(tasks))
+		for _, task := range tasks {
+			items = append(items, mailer.DigestTask{
+				ID:       task.ID,
+				Summary:  task.Summary,
+				TechName: techNames[task.UserID],
+				Date:     date(task).Format(time.RFC3339),
+			})
+		}
+		return items
+	}
+
+	content, err := ctrl.Templates.Render(mailer.TemplateDigest, manager.Locale, mailer.DigestData{
+		ManagerName: manager.Username,
+		Frequency:   p.Frequency,
+		From:        p.From.Format(time.RFC3339),
+		To:          p.To.Format(time.RFC3339),
+		Completed:   digestTasks(completed, func(t entities.Task) time.Time { return *t.PerformedAt }),
+		Created:     digestTasks(created, func(t entities.Task) time.Time { return t.CreatedAt }),
+		Overdue:     digestTasks(overdue, func(t entities.Task) time.Time { return t.CreatedAt }),
+	})
+	if err != nil {
+		return err
+	}
+
+	mail := mailer.Mail{
+		From: mailer.EmailInfo{
+			Name:  ctrl.Config.EmailSenderName,
+			Email: ctrl.Config.EmailSender,
+		},
+		To: []mailer.EmailInfo{
+			{
+				Name:  manager.Username,
+				Email: manager.Email,
+			},
+		},
+		Subject:     content.Subject,
+		PlainText:   content.PlainText,
+		HTML:        content.HTML,
+		Attachments: content.Attachments,
+	}
+
+	return ctrl.Mailer.Send(mail)
+}
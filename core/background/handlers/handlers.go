@@ -8,35 +8,62 @@ import (
 	"github.com/hibiken/asynq"
 
 	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/core/background"
 	"github.com/mirusky-dev/challenge-18/core/background/events"
 	"github.com/mirusky-dev/challenge-18/core/env"
 	"github.com/mirusky-dev/challenge-18/core/mailer"
+	"github.com/mirusky-dev/challenge-18/core/webhook"
 	"github.com/mirusky-dev/challenge-18/models/dtos"
 	"github.com/mirusky-dev/challenge-18/repositories"
 )
 
 type Controller struct {
 	Config           env.Config
+	Clock            core.Clock
 	Mailer           mailer.Mailer
+	Templates        mailer.TemplateRenderer
 	BackgroundClient *asynq.Client
+	WebhookSender    webhook.Sender
 
-	UserRepository repositories.IUserRepository
-	TaskRepository repositories.ITaskRepository
+	UserRepository         repositories.IUserRepository
+	TaskRepository         repositories.ITaskRepository
+	WebhookRepository      repositories.IWebhookRepository
+	NotificationRepository repositories.INotificationRepository
 }
 
-func (ctrl *Controller) HandleTaskCompleted(ctx context.Context, t *asynq.Task) error {
-	var p events.TaskCompletedPayload
+func (ctrl *Controller) HandleMailSend(ctx context.Context, t *asynq.Task) error {
+	var p events.MailSendPayload
 	if err := json.Unmarshal(t.Payload(), &p); err != nil {
 		return fmt.Errorf("json.Unmarshal failed: %v: %w", err, asynq.SkipRetry)
 	}
 
-	userEntity, err := ctrl.UserRepository.GetByID(ctx, p.UserID)
+	return ctrl.Mailer.Send(p.Mail)
+}
+
+// Subscribe registers the event handlers on the bus
+func (ctrl *Controller) Subscribe(bus background.Subscriber) {
+	background.On(bus, "notifications", ctrl.HandleTaskCreated)
+	background.On(bus, "notifications", ctrl.HandleTaskCompleted)
+	background.On(bus, "notifications", ctrl.HandleTaskDeleted)
+
+	for _, event := range events.WebhookEvents {
+		bus.Subscribe(event, "webhooks", ctrl.DispatchWebhooks)
+	}
+}
+
+func (ctrl *Controller) HandleTaskCompleted(ctx context.Context, e background.Envelope, event events.TaskCompleted) error {
+	userEntity, err := ctrl.UserRepository.GetByID(ctx, event.UserID)
 	if err != nil {
 		return err
 	}
 
+	// Nobody to notify
+	if userEntity.Manager == nil {
+		return nil
+	}
+
 	appCtx := core.NewUserCtx(userEntity.ID, []string{userEntity.Role}, []string{})
-	taskEntity, err := ctrl.TaskRepository.GetByID(core.NewContext(ctx, appCtx), p.TaskID)
+	taskEntity, err := ctrl.TaskRepository.GetByID(core.NewContext(ctx, appCtx), event.ID)
 	if err != nil {
 		return err
 	}
@@ -48,6 +75,36 @@ func (ctrl *Controller) HandleTaskCompleted(ctx context.Context, t *asynq.Task)
 	var task dtos.Task
 	task.FromEntity(taskEntity)
 
+	preference, errNotify := ctrl.notify(ctx, manager.ID, events.TypeTaskCompleted,
+		fmt.Sprintf("%s completed the task %q", tech.Username, task.Summary),
+		NotificationData{
+			TaskID:      task.ID,
+			TaskSummary: task.Summary,
+			ActorID:     tech.ID,
+			ActorName:   tech.Username,
+		},
+	)
+	if errNotify != nil {
+		return errNotify
+	}
+
+	// The managers in digest mode receive it later, within the summary
+	if !preference.WantsEmail() || userEntity.Manager.WantsDigest() {
+		return nil
+	}
+
+	content, errRender := ctrl.Templates.Render(mailer.TemplateTaskCompleted, userEntity.Manager.Locale, mailer.TaskCompletedData{
+		ManagerName: manager.Username,
+		TechName:    tech.Username,
+		TechID:      tech.ID,
+		TaskID:      task.ID,
+		TaskSummary: task.Summary,
+		PerformedAt: *task.PerformedAt,
+	})
+	if errRender != nil {
+		return errRender
+	}
+
 	mail := mailer.Mail{
 		From: mailer.EmailInfo{
 			Name:  ctrl.Config.EmailSenderName,
@@ -59,13 +116,10 @@ func (ctrl *Controller) HandleTaskCompleted(ctx context.Context, t *asynq.Task)
 				Email: manager.Email,
 			},
 		},
-		Subject: "Task Completed",
-		PlainText: fmt.Sprintf("The tech %s (%s) performed the task %s on date %s",
-			tech.Username,
-			tech.ID,
-			task.ID,
-			*task.PerformedAt,
-		),
+		Subject:     content.Subject,
+		PlainText:   content.PlainText,
+		HTML:        content.HTML,
+		Attachments: content.Attachments,
 	}
 
 	return ctrl.Mailer.Send(mail)
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/core/background"
+	"github.com/mirusky-dev/challenge-18/core/background/events"
+	"github.com/mirusky-dev/challenge-18/models/entities"
+)
+
+// NotificationData is stored with each notification so the client can link it to the task
+type NotificationData struct {
+	TaskID      string `json:"taskId"`
+	TaskSummary string `json:"taskSummary"`
+	ActorID     string `json:"actorId"`
+	ActorName   string `json:"actorName"`
+}
+
+// notify creates an in-app notification when the user didn't opt out of it, and returns the user preference
+// so the caller can decide about the other channels
+func (ctrl *Controller) notify(ctx context.Context, userID, event, message string, data NotificationData) (entities.NotificationPreference, error) {
+	preference, exception := ctrl.NotificationRepository.GetPreference(ctx, userID, event)
+	if exception != nil {
+		return preference, exception
+	}
+
+	if !preference.WantsInApp() {
+		return preference, nil
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return preference, err
+	}
+
+	if _, exception := ctrl.NotificationRepository.Create(ctx, entities.Notification{
+		UserID:  userID,
+		Event:   event,
+		Message: message,
+		Data:    string(b),
+	}); exception != nil {
+		return preference, exception
+	}
+
+	return preference, nil
+}
+
+// HandleTaskCreated lets the manager know a tech created a task
+func (ctrl *Controller) HandleTaskCreated(ctx context.Context, e background.Envelope, event events.TaskCreated) error {
+	userEntity, err := ctrl.UserRepository.GetByID(ctx, event.UserID)
+	if err != nil {
+		return err
+	}
+
+	// Nobody to notify
+	if userEntity.Manager == nil {
+		return nil
+	}
+
+	appCtx := core.NewUserCtx(userEntity.ID, []string{userEntity.Role}, []string{})
+	taskEntity, err := ctrl.TaskRepository.GetByID(core.NewContext(ctx, appCtx), event.ID)
+	if err != nil {
+		return err
+	}
+
+	_, errNotify := ctrl.notify(ctx, userEntity.Manager.ID, events.TypeTaskCreated,
+		fmt.Sprintf("%s created the task %q", userEntity.Username, taskEntity.Summary),
+		NotificationData{
+			TaskID:      taskEntity.ID,
+			TaskSummary: taskEntity.Summary,
+			ActorID:     userEntity.ID,
+			ActorName:   userEntity.Username,
+		},
+	)
+
+	return errNotify
+}
+
+// HandleTaskDeleted lets the tech know one of their tasks was deleted by a manager
+func (ctrl *Controller) HandleTaskDeleted(ctx context.Context, e background.Envelope, event events.TaskDeleted) error {
+	userEntity, err := ctrl.UserRepository.GetByID(ctx, event.UserID)
+	if err != nil {
+		return err
+	}
+
+	data := NotificationData{
+		TaskID:      event.ID,
+		TaskSummary: event.Summary,
+	}
+
+	if userEntity.Manager != nil {
+		data.ActorID = userEntity.Manager.ID
+		data.ActorName = userEntity.Manager.Username
+	}
+
+	_, errNotify := ctrl.notify(ctx, userEntity.ID, events.TypeTaskDeleted,
+		fmt.Sprintf("The task %q was deleted", event.Summary),
+		data,
+	)
+
+	return errNotify
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/core/background"
+	"github.com/mirusky-dev/challenge-18/core/background/events"
+	"github.com/mirusky-dev/challenge-18/core/webhook"
+	"github.com/mirusky-dev/challenge-18/models/entities"
+)
+
+// DispatchWebhooks creates a delivery for each webhook subscribed to the event
+func (ctrl *Controller) DispatchWebhooks(ctx context.Context, e background.Envelope) error {
+	webhooks, exception := ctrl.WebhookRepository.FindByEvent(ctx, e.Type)
+	if exception != nil {
+		return exception
+	}
+
+	body, err := json.Marshal(events.WebhookBody{
+		ID:         e.ID,
+		Event:      e.Type,
+		OccurredAt: e.OccurredAt,
+		Data:       e.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, w := range webhooks {
+		// The delivery ID is derived from the event and the webhook, so retrying the dispatch doesn't duplicate deliveries
+		deliveryID := uuid.NewSHA1(uuid.NameSpaceOID, []byte(e.ID+":"+w.ID)).String()
+
+		// Only a missing delivery is created, any other failure is returned so the dispatch is retried
+		_, exception := ctrl.WebhookRepository.GetDeliveryByID(ctx, deliveryID)
+		switch {
+		case exception == nil:
+		case exception.Code == core.CodeNotFound:
+			if _, exception := ctrl.WebhookRepository.CreateDelivery(ctx, entities.WebhookDelivery{
+				ID:        deliveryID,
+				WebhookID: w.ID,
+				Event:     e.Type,
+				Payload:   string(body),
+			}); exception != nil {
+				return exception
+			}
+		default:
+			return exception
+		}
+
+		task, err := events.NewWebhookDeliver(ctx, deliveryID)
+		if err != nil {
+			return err
+		}
+
+		if _, err := background.Enqueue(ctx, ctrl.BackgroundClient, task); err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// HandleWebhookDeliver sends the delivery to the webhook receiver, a non 2xx response is retried with backoff
+func (ctrl *Controller) HandleWebhookDeliver(ctx context.Context, t *asynq.Task) error {
+	var p events.WebhookDeliverPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("json.Unmarshal failed: %v: %w", err, asynq.SkipRetry)
+	}
+
+	delivery, exception := ctrl.WebhookRepository.GetDeliveryByID(ctx, p.DeliveryID)
+	if exception != nil {
+		return exception
+	}
+
+	if delivery.DeliveredAt != nil {
+		return nil
+	}
+
+	w, exception := ctrl.WebhookRepository.GetByID(ctx, delivery.WebhookID)
+	if exception != nil {
+		// The webhook was deleted, there is nowhere to deliver
+		return fmt.Errorf("webhook %s: %v: %w", delivery.WebhookID, exception, asynq.SkipRetry)
+	}
+
+	if w.Active != nil && !*w.Active {
+		return nil
+	}
+
+	res, err := ctrl.WebhookSender.Send(ctx, webhook.Request{
+		URL:        w.URL,
+		Secret:     w.Secret,
+		Event:      delivery.Event,
+		DeliveryID: delivery.ID,
+		Body:       []byte(delivery.Payload),
+	})
+
+	delivery.Attempts++
+	delivery.StatusCode = res.StatusCode
+	delivery.Response = res.Body
+	delivery.Error = ""
+	if err != nil {
+		delivery.Error = err.Error()
+	} else {
+		now := ctrl.Clock.Now()
+		delivery.DeliveredAt = &now
+	}
+
+	if exception := ctrl.WebhookRepository.SaveDelivery(ctx, delivery); exception != nil {
+		return exception
+	}
+
+	return err
+}
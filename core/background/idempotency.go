@@ -0,0 +1,113 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const idempotencyPrefix = "gobp.idempotency:"
+
+const (
+	idempotencyRunning = "running"
+	idempotencyDone    = "done"
+)
+
+type redisIdempotencyStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewRedisIdempotencyStore keeps the keys in Redis for ttl, it should be longer than the longest retry window
+func NewRedisIdempotencyStore(client *redis.Client, ttl time.Duration) IdempotencyStore {
+	return &redisIdempotencyStore{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+// Claim uses SETNX, of concurrent attempts only one gets the key
+func (s *redisIdempotencyStore) Claim(ctx context.Context, key string, lease time.Duration) (Claim, error) {
+	ok, err := s.client.SetNX(ctx, idempotencyPrefix+key, idempotencyRunning, lease).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	if ok {
+		return Claimed, nil
+	}
+
+	state, err := s.client.Get(ctx, idempotencyPrefix+key).Result()
+	if errors.Is(err, redis.Nil) {
+		// Released or expired since the SETNX, the next attempt claims it
+		return ClaimHeld, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if state == idempotencyDone {
+		return ClaimDone, nil
+	}
+
+	return ClaimHeld, nil
+}
+
+func (s *redisIdempotencyStore) MarkDone(ctx context.Context, key string) error {
+	return s.client.Set(ctx, idempotencyPrefix+key, idempotencyDone, s.ttl).Err()
+}
+
+func (s *redisIdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.client.Del(ctx, idempotencyPrefix+key).Err()
+}
+
+type memoryIdempotencyEntry struct {
+	state     string
+	expiresAt time.Time
+}
+
+type memoryIdempotencyStore struct {
+	mu   sync.Mutex
+	keys map[string]memoryIdempotencyEntry
+}
+
+// NewMemoryIdempotencyStore keeps the keys in memory, meant for tests
+func NewMemoryIdempotencyStore() IdempotencyStore {
+	return &memoryIdempotencyStore{
+		keys: map[string]memoryIdempotencyEntry{},
+	}
+}
+
+func (s *memoryIdempotencyStore) Claim(ctx context.Context, key string, lease time.Duration) (Claim, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.keys[key]; ok && (entry.state == idempotencyDone || time.Now().Before(entry.expiresAt)) {
+		if entry.state == idempotencyDone {
+			return ClaimDone, nil
+		}
+		return ClaimHeld, nil
+	}
+
+	s.keys[key] = memoryIdempotencyEntry{state: idempotencyRunning, expiresAt: time.Now().Add(lease)}
+	return Claimed, nil
+}
+
+func (s *memoryIdempotencyStore) MarkDone(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[key] = memoryIdempotencyEntry{state: idempotencyDone}
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.keys, key)
+	return nil
+}
@@ -0,0 +1,43 @@
+package background
+
+import (
+	"context"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/core/background/events"
+	"github.com/mirusky-dev/challenge-18/core/mailer"
+)
+
+// queuedMailer doesn't send anything, it enqueues a mail.send task that is delivered by the background worker
+type queuedMailer struct {
+	client *asynq.Client
+	ids    core.IDGenerator
+}
+
+func NewQueuedMailer(client *asynq.Client, ids core.IDGenerator) mailer.ContextMailer {
+	return &queuedMailer{
+		client: client,
+		ids:    ids,
+	}
+}
+
+func (mailer *queuedMailer) Send(email mailer.Mail) error {
+	return mailer.SendContext(context.Background(), email)
+}
+
+func (mailer *queuedMailer) SendContext(ctx context.Context, email mailer.Mail) error {
+	// Fails fast, retrying a mail that can't be sent is pointless
+	if err := email.Validate(); err != nil {
+		return err
+	}
+
+	task, err := events.NewMailSend(ctx, mailer.ids.NewID(), email)
+	if err != nil {
+		return err
+	}
+
+	_, err = Enqueue(ctx, mailer.client, task)
+	return err
+}
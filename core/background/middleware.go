@@ -0,0 +1,292 @@
+package background
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/core/metrics"
+	"github.com/mirusky-dev/challenge-18/core/tracing"
+)
+
+// Recover turns a panic into an error, so the task is retried like any other failure
+func Recover() asynq.MiddlewareFunc {
+	return func(next asynq.Handler) asynq.Handler {
+		return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+				}
+			}()
+
+			return next.ProcessTask(ctx, t)
+		})
+	}
+}
+
+// Correlation puts the correlation ID of the payload in the context, so the task logs can be joined to the request
+// that enqueued it. The tasks enqueued without one, like the scheduled ones, are correlated by their own ID.
+//
+// It must be the first middleware, the payload is peeked without being validated.
+func Correlation() asynq.MiddlewareFunc {
+	return func(next asynq.Handler) asynq.Handler {
+		return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+			var p struct {
+				CorrelationID string
+			}
+			_ = json.Unmarshal(t.Payload(), &p)
+
+			if p.CorrelationID == "" {
+				p.CorrelationID, _ = asynq.GetTaskID(ctx)
+			}
+
+			return next.ProcessTask(core.WithCorrelationID(ctx, p.CorrelationID), t)
+		})
+	}
+}
+
+// Tracing continues the trace carried by the payload events.Metadata, each attempt is a consumer span.
+//
+// It must run before Logging, so the task logs have the trace ID.
+func Tracing() asynq.MiddlewareFunc {
+	return func(next asynq.Handler) asynq.Handler {
+		return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+			var p struct {
+				TraceContext map[string]string
+			}
+			_ = json.Unmarshal(t.Payload(), &p)
+
+			id, _ := asynq.GetTaskID(ctx)
+			queue, _ := asynq.GetQueueName(ctx)
+			retry, _ := asynq.GetRetryCount(ctx)
+
+			ctx, span := tracing.Start(tracing.Extract(ctx, p.TraceContext), "process "+t.Type(),
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(
+					attribute.String("messaging.system", "asynq"),
+					attribute.String("messaging.operation", "process"),
+					attribute.String("messaging.message.id", id),
+					attribute.String("messaging.destination.name", queue),
+					attribute.Int("asynq.retry", retry),
+				),
+			)
+
+			err := next.ProcessTask(ctx, t)
+			tracing.End(span, err)
+
+			return err
+		})
+	}
+}
+
+// Logging logs the outcome of each attempt with the task type, id, queue and retry
+func Logging(logger *slog.Logger) asynq.MiddlewareFunc {
+	return func(next asynq.Handler) asynq.Handler {
+		return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+			id, _ := asynq.GetTaskID(ctx)
+			queue, _ := asynq.GetQueueName(ctx)
+			retry, _ := asynq.GetRetryCount(ctx)
+			maxRetry, _ := asynq.GetMaxRetry(ctx)
+
+			start := time.Now()
+			err := next.ProcessTask(ctx, t)
+
+			attrs := []slog.Attr{
+				slog.String("type", t.Type()),
+				slog.String("id", id),
+				slog.String("queue", queue),
+				slog.Int("retry", retry),
+				slog.Int("maxRetry", maxRetry),
+				slog.Duration("duration", time.Since(start)),
+			}
+
+			if err != nil {
+				logger.LogAttrs(ctx, slog.LevelError, "task failed", append(attrs, slog.String("error", err.Error()))...)
+			} else {
+				logger.LogAttrs(ctx, slog.LevelInfo, "task done", attrs...)
+			}
+
+			return err
+		})
+	}
+}
+
+// Metrics counts the processed, failed and retried attempts and observes their duration, by task type
+func Metrics() asynq.MiddlewareFunc {
+	return func(next asynq.Handler) asynq.Handler {
+		return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+			start := time.Now()
+			err := next.ProcessTask(ctx, t)
+
+			metrics.TaskDuration.WithLabelValues(t.Type()).Observe(time.Since(start).Seconds())
+			metrics.TasksProcessed.WithLabelValues(t.Type()).Inc()
+
+			if retry, _ := asynq.GetRetryCount(ctx); retry > 0 {
+				metrics.TasksRetried.WithLabelValues(t.Type()).Inc()
+			}
+
+			if err != nil {
+				metrics.TasksFailed.WithLabelValues(t.Type()).Inc()
+			}
+
+			return err
+		})
+	}
+}
+
+// Errors gives the handlers errors their full detail, and doesn't retry the ones caused by the input (4xx),
+// like a missing user, since they would fail the same way
+func Errors() asynq.MiddlewareFunc {
+	return func(next asynq.Handler) asynq.Handler {
+		return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+			err := next.ProcessTask(ctx, t)
+
+			var exception *core.Exception
+			if err == nil || !errors.As(err, &exception) || exception == nil {
+				return err
+			}
+
+			metrics.Exceptions.WithLabelValues(exception.Code, strconv.Itoa(exception.Status)).Inc()
+
+			detailed := fmt.Errorf("%s (%d %s): %s", exception.Message, exception.Status, exception.Code, exception.Err)
+			if exception.Status >= 400 && exception.Status < 500 {
+				return fmt.Errorf("%v: %w", detailed, asynq.SkipRetry)
+			}
+
+			return detailed
+		})
+	}
+}
+
+// WithPolicies applies the Timeout and MaxRetry of the task type policy
+func WithPolicies(policies map[string]Policy) asynq.MiddlewareFunc {
+	return func(next asynq.Handler) asynq.Handler {
+		return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+			policy, ok := policies[t.Type()]
+			if !ok {
+				return next.ProcessTask(ctx, t)
+			}
+
+			if policy.Timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, policy.Timeout)
+				defer cancel()
+			}
+
+			err := next.ProcessTask(ctx, t)
+			if err == nil || errors.Is(err, asynq.SkipRetry) {
+				return err
+			}
+
+			if retry, _ := asynq.GetRetryCount(ctx); policy.MaxRetry > 0 && retry >= policy.MaxRetry {
+				return fmt.Errorf("%v: %w", err, asynq.SkipRetry)
+			}
+
+			return err
+		})
+	}
+}
+
+// Claim is the outcome of IdempotencyStore.Claim
+type Claim int
+
+const (
+	// Claimed means the attempt can run, it must MarkDone or Release the key
+	Claimed Claim = iota + 1
+	// ClaimHeld means another attempt is running it
+	ClaimHeld
+	// ClaimDone means an attempt already succeeded
+	ClaimDone
+)
+
+// IdempotencyStore remembers the tasks that are running or already succeeded
+type IdempotencyStore interface {
+	// Claim reserves the key for lease, atomically, so concurrent duplicates don't both run
+	Claim(ctx context.Context, key string, lease time.Duration) (Claim, error)
+	MarkDone(ctx context.Context, key string) error
+	Release(ctx context.Context, key string) error
+}
+
+// defaultIdempotencyLease is the asynq default timeout, the lease of the tasks without a policy Timeout
+const defaultIdempotencyLease = 30 * time.Minute
+
+// taskID is asynq.GetTaskID, replaced by the tests
+var taskID = asynq.GetTaskID
+
+// IdempotencyKey identifies a task by its type and the ID given when it was enqueued (asynq.TaskID), so the
+// duplicates are the tasks enqueued with the same ID, not the ones with the same content.
+//
+// It's false out of the worker, when the task has no ID.
+func IdempotencyKey(ctx context.Context, t *asynq.Task) (string, bool) {
+	id, ok := taskID(ctx)
+	if !ok || id == "" {
+		return "", false
+	}
+
+	return t.Type() + ":" + id, true
+}
+
+// Idempotency skips the tasks of Idempotent types that already succeeded, and retries later the ones another attempt
+// is running.
+//
+// A failure of the store doesn't fail the task, running it twice is better than not running it.
+func Idempotency(store IdempotencyStore, policies map[string]Policy) asynq.MiddlewareFunc {
+	return func(next asynq.Handler) asynq.Handler {
+		return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+			policy := policies[t.Type()]
+			if !policy.Idempotent {
+				return next.ProcessTask(ctx, t)
+			}
+
+			key, ok := IdempotencyKey(ctx, t)
+			if !ok {
+				return next.ProcessTask(ctx, t)
+			}
+
+			// Outlives the attempt, so the key isn't free while it runs
+			lease := defaultIdempotencyLease
+			if policy.Timeout > 0 {
+				lease = policy.Timeout + time.Minute
+			}
+
+			claim, err := store.Claim(ctx, key, lease)
+			if err != nil {
+				slog.WarnContext(ctx, "failed to claim the idempotency key", "key", key, "error", err)
+				return next.ProcessTask(ctx, t)
+			}
+
+			switch claim {
+			case ClaimDone:
+				return nil
+			case ClaimHeld:
+				return fmt.Errorf("%s is already running", key)
+			}
+
+			// The attempt may have timed out, the key is still updated
+			storeCtx := context.WithoutCancel(ctx)
+
+			if err := next.ProcessTask(ctx, t); err != nil {
+				if err := store.Release(storeCtx, key); err != nil {
+					slog.WarnContext(ctx, "failed to release the idempotency key", "key", key, "error", err)
+				}
+				return err
+			}
+
+			if err := store.MarkDone(storeCtx, key); err != nil {
+				slog.WarnContext(ctx, "failed to store the idempotency key", "key", key, "error", err)
+			}
+
+			return nil
+		})
+	}
+}
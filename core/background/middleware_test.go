@@ -0,0 +1,283 @@
+package background
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hibiken/asynq"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/core/background/events"
+	"github.com/mirusky-dev/challenge-18/core/metrics"
+	"github.com/mirusky-dev/challenge-18/core/tracing"
+)
+
+func chain(handler asynq.HandlerFunc, middlewares ...asynq.MiddlewareFunc) asynq.Handler {
+	var h asynq.Handler = handler
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+func TestRecover(t *testing.T) {
+	h := chain(func(ctx context.Context, t *asynq.Task) error {
+		var manager *struct{ Name string }
+		_ = manager.Name
+		return nil
+	}, Recover())
+
+	err := h.ProcessTask(context.Background(), asynq.NewTask("test", nil))
+	assert.ErrorContains(t, err, "panic: runtime error: invalid memory address")
+}
+
+func TestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := core.NewLogger(&buf, "info", core.LogFormatText)
+	assert.NoError(t, err)
+
+	h := chain(func(ctx context.Context, t *asynq.Task) error {
+		return errors.New("boom")
+	}, Correlation(), Logging(logger))
+
+	_ = h.ProcessTask(context.Background(), asynq.NewTask("test.logging", []byte(`{"CorrelationID":"req-1"}`)))
+
+	line := buf.String()
+	assert.Contains(t, line, `level=ERROR msg="task failed" type=test.logging`)
+	assert.Contains(t, line, `error=boom`)
+	assert.Contains(t, line, `contextId=req-1`)
+}
+
+func TestCorrelation(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload []byte
+		want    string
+	}{
+		{name: "from the payload", payload: []byte(`{"CorrelationID":"req-1"}`), want: "req-1"},
+		{name: "from a json tag", payload: []byte(`{"correlationId":"req-2"}`), want: "req-2"},
+		{name: "not json", payload: []byte("raw"), want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			h := chain(func(ctx context.Context, t *asynq.Task) error {
+				got = core.CorrelationID(ctx)
+				return nil
+			}, Correlation())
+
+			_ = h.ProcessTask(context.Background(), asynq.NewTask("test.correlation", tt.payload))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	ctx, request := tracing.Start(context.Background(), "request")
+	payload, err := json.Marshal(events.NewMetadata(ctx))
+	assert.NoError(t, err)
+	request.End()
+
+	h := chain(func(ctx context.Context, t *asynq.Task) error {
+		return errors.New("boom")
+	}, Tracing())
+
+	_ = h.ProcessTask(context.Background(), asynq.NewTask("test.tracing", payload))
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 2) {
+		process := spans[1]
+		assert.Equal(t, "process test.tracing", process.Name())
+		assert.Equal(t, request.SpanContext().TraceID(), process.SpanContext().TraceID(), "the task continues the request trace")
+		assert.Equal(t, codes.Error, process.Status().Code)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	processed := testutil.ToFloat64(metrics.TasksProcessed.WithLabelValues("test.metrics"))
+	failed := testutil.ToFloat64(metrics.TasksFailed.WithLabelValues("test.metrics"))
+
+	fail := true
+	h := chain(func(ctx context.Context, t *asynq.Task) error {
+		if fail {
+			return errors.New("boom")
+		}
+		return nil
+	}, Metrics())
+
+	_ = h.ProcessTask(context.Background(), asynq.NewTask("test.metrics", nil))
+	fail = false
+	_ = h.ProcessTask(context.Background(), asynq.NewTask("test.metrics", nil))
+
+	assert.Equal(t, processed+2, testutil.ToFloat64(metrics.TasksProcessed.WithLabelValues("test.metrics")))
+	assert.Equal(t, failed+1, testutil.ToFloat64(metrics.TasksFailed.WithLabelValues("test.metrics")))
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantSkip  bool
+		wantError string
+	}{
+		{name: "not found is not retried", err: core.NotFound(), wantSkip: true, wantError: "(404"},
+		{name: "unexpected is retried with its detail", err: core.Unexpected(core.WithError(errors.New("connection refused"))), wantError: "connection refused"},
+		{name: "other errors are untouched", err: errors.New("boom"), wantError: "boom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := chain(func(ctx context.Context, t *asynq.Task) error { return tt.err }, Errors())
+
+			err := h.ProcessTask(context.Background(), asynq.NewTask("test", nil))
+			assert.Equal(t, tt.wantSkip, errors.Is(err, asynq.SkipRetry))
+			assert.ErrorContains(t, err, tt.wantError)
+		})
+	}
+}
+
+func TestWithPolicies(t *testing.T) {
+	policies := map[string]Policy{
+		"test.slow": {Timeout: 10 * time.Millisecond},
+	}
+
+	h := chain(func(ctx context.Context, t *asynq.Task) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, WithPolicies(policies))
+
+	err := h.ProcessTask(context.Background(), asynq.NewTask("test.slow", nil))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// withTaskID makes IdempotencyKey read the task ID from the ctx, like asynq.GetTaskID does in the worker
+func withTaskID(t *testing.T) func(ctx context.Context, id string) context.Context {
+	type key struct{}
+
+	previous := taskID
+	taskID = func(ctx context.Context) (string, bool) {
+		id, ok := ctx.Value(key{}).(string)
+		return id, ok
+	}
+	t.Cleanup(func() { taskID = previous })
+
+	return func(ctx context.Context, id string) context.Context {
+		return context.WithValue(ctx, key{}, id)
+	}
+}
+
+func TestIdempotency(t *testing.T) {
+	withID := withTaskID(t)
+
+	policies := map[string]Policy{
+		"test.idempotent": {Idempotent: true},
+	}
+
+	calls := 0
+	fail := true
+	h := chain(func(ctx context.Context, t *asynq.Task) error {
+		calls++
+		if fail {
+			return errors.New("boom")
+		}
+		return nil
+	}, Idempotency(NewMemoryIdempotencyStore(), policies))
+
+	task := asynq.NewTask("test.idempotent", []byte(`{"id":1}`))
+	ctx := withID(context.Background(), "task-1")
+
+	assert.Error(t, h.ProcessTask(ctx, task))
+
+	fail = false
+	assert.NoError(t, h.ProcessTask(ctx, task))
+	assert.NoError(t, h.ProcessTask(ctx, task))
+	assert.Equal(t, 2, calls, "a failed attempt shouldn't be remembered and a succeeded one shouldn't run again")
+
+	assert.NoError(t, h.ProcessTask(withID(context.Background(), "task-2"), task))
+	assert.Equal(t, 3, calls, "the same payload with another task ID should run")
+
+	assert.NoError(t, h.ProcessTask(context.Background(), task))
+	assert.Equal(t, 4, calls, "a task without ID isn't deduplicated")
+}
+
+func TestIdempotencyConcurrent(t *testing.T) {
+	withID := withTaskID(t)
+
+	policies := map[string]Policy{
+		"test.idempotent": {Idempotent: true},
+	}
+
+	running := make(chan struct{})
+	release := make(chan struct{})
+	h := chain(func(ctx context.Context, t *asynq.Task) error {
+		close(running)
+		<-release
+		return nil
+	}, Idempotency(NewMemoryIdempotencyStore(), policies))
+
+	task := asynq.NewTask("test.idempotent", nil)
+	ctx := withID(context.Background(), "task-1")
+
+	done := make(chan error)
+	go func() { done <- h.ProcessTask(ctx, task) }()
+	<-running
+
+	assert.ErrorContains(t, h.ProcessTask(ctx, task), "already running", "the duplicate is retried while the first one runs")
+
+	close(release)
+	assert.NoError(t, <-done)
+	assert.NoError(t, h.ProcessTask(ctx, task), "the duplicate is skipped once the first one succeeded")
+}
+
+func TestRedisIdempotencyStore(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	store := NewRedisIdempotencyStore(client, time.Hour)
+	ctx := context.Background()
+
+	claim, err := store.Claim(ctx, "key", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, Claimed, claim)
+
+	claim, err = store.Claim(ctx, "key", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, ClaimHeld, claim)
+
+	assert.NoError(t, store.Release(ctx, "key"))
+	claim, err = store.Claim(ctx, "key", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, Claimed, claim, "a failed attempt frees the key")
+
+	assert.NoError(t, store.MarkDone(ctx, "key"))
+	claim, err = store.Claim(ctx, "key", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, ClaimDone, claim)
+}
+
+func TestRetryDelay(t *testing.T) {
+	backoff := ExponentialBackoff(10*time.Second, time.Hour)
+
+	assert.GreaterOrEqual(t, backoff(0), 10*time.Second)
+	assert.Less(t, backoff(0), 12*time.Second)
+	assert.GreaterOrEqual(t, backoff(2), 40*time.Second)
+	assert.GreaterOrEqual(t, backoff(30), time.Hour)
+	assert.Less(t, backoff(30), time.Hour+7*time.Minute)
+}
@@ -0,0 +1,73 @@
+package background
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/mirusky-dev/challenge-18/core/background/events"
+)
+
+// Policy tunes how the worker runs a task type, the zero value of a field keeps the asynq behavior
+type Policy struct {
+	// Timeout limits each attempt, it's applied on top of the timeout given when the task was enqueued
+	Timeout time.Duration
+
+	// MaxRetry archives the task after that many retries even if it was enqueued with more
+	MaxRetry int
+
+	// Backoff returns how long to wait before the retry n
+	Backoff func(n int) time.Duration
+
+	// Idempotent skips a task when another one with the same type and task ID already succeeded, and retries it
+	// while another one is running, it requires the Idempotency middleware
+	Idempotent bool
+}
+
+// ExponentialBackoff doubles the delay on each retry, starting at base and up to max, with 10% of jitter
+func ExponentialBackoff(base, max time.Duration) func(n int) time.Duration {
+	return func(n int) time.Duration {
+		delay := time.Duration(float64(base) * math.Pow(2, float64(n)))
+		if delay > max || delay <= 0 {
+			delay = max
+		}
+
+		jitter := time.Duration(rand.Int63n(int64(delay/10) + 1))
+
+		return delay + jitter
+	}
+}
+
+// Policies are the policies of the known task types
+var Policies = map[string]Policy{
+	// A flaky SMTP server or webhook receiver has time to recover
+	events.TypeMailSend: {
+		Timeout:    time.Minute,
+		Backoff:    ExponentialBackoff(10*time.Second, time.Hour),
+		Idempotent: true,
+	},
+	events.TypeWebhookDeliver: {
+		Timeout: time.Minute,
+		Backoff: ExponentialBackoff(10*time.Second, time.Hour),
+	},
+	events.TypeDigestSend: {
+		Timeout:    2 * time.Minute,
+		MaxRetry:   5,
+		Idempotent: true,
+	},
+	typeBusDeliver: {
+		Timeout:    5 * time.Minute,
+		Backoff:    ExponentialBackoff(5*time.Second, 30*time.Minute),
+		Idempotent: true,
+	},
+}
+
+// RetryDelay uses the Backoff of the task type policy, any other task keeps the asynq default
+func RetryDelay(n int, err error, task *asynq.Task) time.Duration {
+	if policy, ok := Policies[task.Type()]; ok && policy.Backoff != nil {
+		return policy.Backoff(n)
+	}
+
+	return asynq.DefaultRetryDelayFunc(n, err, task)
+}
@@ -0,0 +1,22 @@
+package background
+
+import (
+	"log/slog"
+
+	"github.com/hibiken/asynq"
+	"github.com/mirusky-dev/challenge-18/core/env"
+)
+
+// NewScheduler creates a scheduler to enqueue periodic tasks, the cron specs are evaluated in UTC
+func NewScheduler(config env.Config) (*asynq.Scheduler, error) {
+
+	redisOpt, err := redisConnOpt(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return asynq.NewScheduler(redisOpt, &asynq.SchedulerOpts{
+		Logger:   NewLogger(slog.Default()),
+		LogLevel: asynq.DebugLevel,
+	}), nil
+}
@@ -1,42 +1,51 @@
 package background
 
 import (
-	"log"
+	"fmt"
+	"log/slog"
+	"os"
 	"time"
 
 	"github.com/hibiken/asynq"
+	"github.com/mirusky-dev/challenge-18/core/background/events"
 	"github.com/mirusky-dev/challenge-18/core/env"
 )
 
-type wrapLogger struct {
-	*log.Logger
+// slogLogger makes asynq log through slog, keeping its levels
+type slogLogger struct {
+	logger *slog.Logger
 }
 
-func (wrap wrapLogger) Debug(args ...interface{}) {
-	wrap.Logger.Print(args...)
+func (l slogLogger) Debug(args ...interface{}) {
+	l.logger.Debug(fmt.Sprint(args...))
 }
 
-func (wrap wrapLogger) Info(args ...interface{}) {
-	wrap.Logger.Print(args...)
+func (l slogLogger) Info(args ...interface{}) {
+	l.logger.Info(fmt.Sprint(args...))
 }
 
-func (wrap wrapLogger) Warn(args ...interface{}) {
-	wrap.Logger.Print(args...)
+func (l slogLogger) Warn(args ...interface{}) {
+	l.logger.Warn(fmt.Sprint(args...))
 }
 
-func (wrap wrapLogger) Error(args ...interface{}) {
-	wrap.Logger.Print(args...)
+func (l slogLogger) Error(args ...interface{}) {
+	l.logger.Error(fmt.Sprint(args...))
 }
 
-func NewWrapLogger() asynq.Logger {
-	return wrapLogger{
-		log.Default(),
+func (l slogLogger) Fatal(args ...interface{}) {
+	l.logger.Error(fmt.Sprint(args...))
+	os.Exit(1)
+}
+
+func NewLogger(logger *slog.Logger) asynq.Logger {
+	return slogLogger{
+		logger: logger.With("component", "asynq"),
 	}
 }
 
 func NewServerMux(config env.Config) (*asynq.Server, *asynq.ServeMux, error) {
 
-	redisOpt, err := asynq.ParseRedisURI(config.RedisURL + "/12")
+	redisOpt, err := redisConnOpt(config)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -44,20 +53,25 @@ func NewServerMux(config env.Config) (*asynq.Server, *asynq.ServeMux, error) {
 	srv := asynq.NewServer(
 		redisOpt,
 		asynq.Config{
-			Logger: NewWrapLogger(),
+			Logger: NewLogger(slog.Default()),
+			// The level is filtered by the slog handler
+			LogLevel: asynq.DebugLevel,
 			// Specify how many concurrent workers to use
 			Concurrency: 10,
 			// Optionally specify multiple queues with different priority.
 			Queues: map[string]int{
-				"highest": 3,
-				"high":    3,
-				"default": 2,
-				"low":     1,
-				"lowest":  1,
+				"highest":           3,
+				"high":              3,
+				"default":           2,
+				QueueEvents:         2,
+				events.QueueMail:    2,
+				events.QueueWebhook: 1,
+				"low":               1,
+				"lowest":            1,
 			},
 			// See the godoc for other configuration options
-			// LogLevel:        asynq.FatalLevel,
 			ShutdownTimeout: 5 * time.Second,
+			RetryDelayFunc:  RetryDelay,
 		},
 	)
 
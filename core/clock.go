@@ -0,0 +1,42 @@
+package core
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Clock tells the current time, the services take it instead of calling time.Now so the tests control it
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewSystemClock returns the wall clock
+func NewSystemClock() Clock {
+	return systemClock{}
+}
+
+// IDGenerator generates the IDs of the entities and of the issued tokens.
+//
+// The IDs aren't secrets: links and refresh tokens that must not be guessed keep using random UUIDs.
+type IDGenerator interface {
+	NewID() string
+}
+
+type uuidV7Generator struct{}
+
+func (uuidV7Generator) NewID() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// NewUUIDv7Generator generates time-ordered UUIDs, consecutive inserts land next to each other in the primary key
+// index instead of spreading over it like random UUIDs
+func NewUUIDv7Generator() IDGenerator {
+	return uuidV7Generator{}
+}
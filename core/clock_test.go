@@ -0,0 +1,26 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUUIDv7Generator(t *testing.T) {
+	ids := NewUUIDv7Generator()
+
+	previous := ids.NewID()
+	for i := 0; i < 100; i++ {
+		id := ids.NewID()
+
+		parsed, err := uuid.Parse(id)
+		require.NoError(t, err)
+		assert.Equal(t, uuid.Version(7), parsed.Version())
+
+		// Sorted like they were generated, so the inserts append to the index
+		assert.Greater(t, id, previous)
+		previous = id
+	}
+}
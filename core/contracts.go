@@ -19,3 +19,10 @@ type IBaseService[IDType, Create, Update, Result any] interface {
 	Update(ctx context.Context, id IDType, changes Update) (*Result, *Exception)
 	Delete(ctx context.Context, id IDType) *Exception
 }
+
+// UnitOfWork runs the repository calls made with the context given to fn in a single transaction: it's committed when
+// fn returns nil and rolled back when it returns an exception or panics. A unit of work started within another one
+// joins it as a savepoint.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) *Exception) *Exception
+}
@@ -2,7 +2,6 @@ package core
 
 import (
 	"context"
-	"fmt"
 	"strings"
 
 	"github.com/google/uuid"
@@ -33,7 +32,7 @@ func (hr HasRole) Evaluate(appCtx Ctx) *Exception {
 		}
 	}
 
-	return Forbidden(WithMessage(fmt.Sprintf("user does not have required role: %s", requiredRole)))
+	return Forbidden(WithMessageKey(MessageMissingRole, map[string]any{"roles": requiredRole}))
 }
 
 func (hp HasPermission) Evaluate(appCtx Ctx) *Exception {
@@ -43,7 +42,7 @@ func (hp HasPermission) Evaluate(appCtx Ctx) *Exception {
 		}
 	}
 
-	return Forbidden(WithMessage(fmt.Sprintf("user does not have required permission: %s", hp.Permission)))
+	return Forbidden(WithMessageKey(MessageMissingPermission, map[string]any{"permissions": hp.Permission}))
 }
 
 type HasRoles struct {
@@ -64,9 +63,9 @@ func (hr HasRoles) Evaluate(appCtx Ctx) *Exception {
 	hasOne := slices.Contains(checks, true)
 
 	if hr.All && !hasAll {
-		return Forbidden(WithMessage(fmt.Sprintf("user does not have required roles: %s", strings.Join(requiredRoles, ","))))
+		return Forbidden(WithMessageKey(MessageMissingRole, map[string]any{"roles": strings.Join(requiredRoles, ",")}))
 	} else if !hr.All && !hasOne {
-		return Forbidden(WithMessage(fmt.Sprintf("user does not have required roles: %s", strings.Join(requiredRoles, ","))))
+		return Forbidden(WithMessageKey(MessageMissingRole, map[string]any{"roles": strings.Join(requiredRoles, ",")}))
 	}
 
 	return nil
@@ -85,9 +84,9 @@ func (hp HasPermissions) Evaluate(appCtx Ctx) *Exception {
 	hasOne := slices.Contains(checks, true)
 
 	if hp.All && !hasAll {
-		return Forbidden(WithMessage(fmt.Sprintf("user does not have required permissions: %s", strings.Join(requiredPermissions, ","))))
+		return Forbidden(WithMessageKey(MessageMissingPermission, map[string]any{"permissions": strings.Join(requiredPermissions, ",")}))
 	} else if !hp.All && !hasOne {
-		return Forbidden(WithMessage(fmt.Sprintf("user does not have required permissions: %s", strings.Join(requiredPermissions, ","))))
+		return Forbidden(WithMessageKey(MessageMissingPermission, map[string]any{"permissions": strings.Join(requiredPermissions, ",")}))
 	}
 
 	return nil
@@ -178,3 +177,25 @@ func FromContext(ctx context.Context) (Ctx, bool) {
 func NewContext(parent context.Context, v Ctx) context.Context {
 	return context.WithValue(parent, userCtxKey{}, v)
 }
+
+type correlationKey struct{}
+
+// WithCorrelationID returns a copy of parent carrying the correlation ID, the background worker uses it to join its
+// logs to the request that enqueued the task
+func WithCorrelationID(parent context.Context, id string) context.Context {
+	return context.WithValue(parent, correlationKey{}, id)
+}
+
+// CorrelationID returns the ID shared by the logs of a request and of the tasks it enqueued, it's the Context-ID of
+// the request unless WithCorrelationID set another one
+func CorrelationID(ctx context.Context) string {
+	if id, ok := ctx.Value(correlationKey{}).(string); ok && id != "" {
+		return id
+	}
+
+	if appCtx, ok := FromContext(ctx); ok {
+		return appCtx.ID()
+	}
+
+	return ""
+}
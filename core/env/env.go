@@ -5,6 +5,7 @@ import (
 	"os"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/caarlos0/env"
 	"github.com/joho/godotenv"
@@ -25,7 +26,7 @@ const (
 )
 
 type Config struct {
-	DatabaseURL string `env:"DATABASE_URL"`
+	DatabaseURL string `env:"DATABASE_URL" secret:"true"`
 	Port        string `env:"PORT"`
 	Version     string `env:"VERSION"`
 	Environment string `env:"ENVIRONMENT"`
@@ -36,17 +37,39 @@ type Config struct {
 
 	SkipMigration bool `env:"SKIP_MIGRATION"`
 
-	SendgridAPIKey   string `env:"SENDGRID_API_KEY"`
+	EnableDebug bool `env:"ENABLE_DEBUG"` // mounts /api/debug, outside DEV only admins can use it
+
+	LogLevel  string `env:"LOG_LEVEL"`  // debug, info, warn or error
+	LogFormat string `env:"LOG_FORMAT"` // json or text
+
+	MetricsPort    string `env:"METRICS_PORT"`     // the background worker serves /metrics and the health checks on it
+	APIMetricsPort string `env:"API_METRICS_PORT"` // the API serves /metrics and /status on it, out of the public PORT
+
+	TracingExporter    string  `env:"TRACING_EXPORTER"`     // none, stdout or otlp
+	TracingSampleRatio float64 `env:"TRACING_SAMPLE_RATIO"` // 0 to 1, of the traces started here
+
+	Mailer           string `env:"MAILER"` // noop, smtp, sendgrid, file or memory
+	MailerFileDir    string `env:"MAILER_FILE_DIR"`
+	SendgridAPIKey   string `env:"SENDGRID_API_KEY" secret:"true"`
 	SMTPURL          string `env:"SMTP_URL"`
 	SMTPPort         string `env:"SMTP_PORT"`
 	SMTPClientID     string `env:"SMTP_CLIENT_ID"`
-	SMTPClientSecret string `env:"SMTP_CLIENT_SECRET"`
+	SMTPClientSecret string `env:"SMTP_CLIENT_SECRET" secret:"true"`
+	SMTPEncryption   string `env:"SMTP_ENCRYPTION"` // none, ssl or starttls
 	EmailSender      string `env:"EMAIL_SENDER"`
 	EmailSenderName  string `env:"EMAIL_SENDER_NAME"`
 
-	JWTSecret string `env:"JWT_SECRET"`
+	MailTemplatesDir string `env:"MAIL_TEMPLATES_DIR"`
+	MailBrandName    string `env:"MAIL_BRAND_NAME"`
+	MailBrandURL     string `env:"MAIL_BRAND_URL"`
+
+	DigestDailyCron    string        `env:"DIGEST_DAILY_CRON"`    // UTC
+	DigestWeeklyCron   string        `env:"DIGEST_WEEKLY_CRON"`   // UTC
+	DigestOverdueAfter time.Duration `env:"DIGEST_OVERDUE_AFTER"` // an open task older than it is overdue
 
-	RedisURL string `env:"REDIS_URL"`
+	JWTSecret string `env:"JWT_SECRET" secret:"true"`
+
+	RedisURL string `env:"REDIS_URL" secret:"true"`
 }
 
 // Load Loads .env files and/or values from enviroment variables
@@ -61,12 +84,28 @@ func Load(basePathOverwrite ...string) (Config, error) {
 		EnablePrintRoutes:    false,
 		EnableStackTrace:     false,
 		SkipMigration:        false,
+		LogLevel:             "info",
+		LogFormat:            "json",
+		MetricsPort:          "9091",
+		APIMetricsPort:       "9095",
+		TracingExporter:      "none",
+		TracingSampleRatio:   1,
+		Mailer:               "noop",
+		MailerFileDir:        path.Join(".", "tmp", "mails"),
+		SMTPEncryption:       "starttls",
 		EmailSender:          "no-reply@golangboilerplate.com",
 		EmailSenderName:      "Golang Boilerplate (No Reply)",
+		MailBrandName:        "Golang Boilerplate",
+		DigestDailyCron:      "0 8 * * *",
+		DigestWeeklyCron:     "0 8 * * 1",
+		DigestOverdueAfter:   72 * time.Hour,
 	}
 
 	environ := strings.ToUpper(Get("ENVIRONMENT", config.Environment))
 
+	// The diagnostics are on by default only while developing
+	config.EnableDebug = environ == DEV
+
 	basePath := path.Join(".", "configs")
 	if len(basePathOverwrite) > 0 {
 		basePath = basePathOverwrite[0]
@@ -88,6 +127,11 @@ func Load(basePathOverwrite ...string) (Config, error) {
 	return config, err
 }
 
+// IsDev reports whether it's running in the DEV environment
+func (c Config) IsDev() bool {
+	return strings.EqualFold(c.Environment, DEV)
+}
+
 // Get gets environment variable or the default value
 func Get(key, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
@@ -0,0 +1,59 @@
+package env
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// Redacted returns the config by env variable name, the fields tagged `secret:"true"` are redacted.
+//
+// The credentials of an URL or DSN are replaced but the host is kept, it's what's usually being checked.
+func (c Config) Redacted() map[string]any {
+	value := reflect.ValueOf(c)
+	typ := value.Type()
+
+	values := make(map[string]any, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+
+		name := field.Tag.Get("env")
+		if name == "" {
+			continue
+		}
+
+		v := value.Field(i).Interface()
+
+		switch {
+		case field.Tag.Get("secret") == "true":
+			v = Redact(value.Field(i).String())
+		case field.Type == reflect.TypeOf(time.Duration(0)):
+			v = v.(time.Duration).String()
+		}
+
+		values[name] = v
+	}
+
+	return values
+}
+
+// Redact hides a secret, e.g: redis://:password@host:6379 becomes redis://:xxxxx@host:6379 and
+// user:password@tcp(host:3306)/db becomes [REDACTED]@tcp(host:3306)/db
+func Redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+
+	if u, err := url.Parse(secret); err == nil && u.User != nil && u.Host != "" {
+		return u.Redacted()
+	}
+
+	if i := strings.LastIndex(secret, "@"); i >= 0 {
+		return redacted + secret[i:]
+	}
+
+	return redacted
+}
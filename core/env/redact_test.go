@@ -0,0 +1,32 @@
+package env
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	assert.Equal(t, "", Redact(""))
+	assert.Equal(t, "redis://:xxxxx@127.0.0.1:6379", Redact("redis://:redis@127.0.0.1:6379"))
+	assert.Equal(t, "[REDACTED]@tcp(127.0.0.1:3306)/database?parseTime=True", Redact("mysql:mysql@tcp(127.0.0.1:3306)/database?parseTime=True"))
+	assert.Equal(t, "[REDACTED]", Redact("5u7IQ0vzeOgNuHRU82l45CnZ6PZ6nj8pZYwpOyV2nPfz"))
+}
+
+func TestConfigRedacted(t *testing.T) {
+	values := Config{
+		Port:               "4000",
+		JWTSecret:          "secret",
+		SendgridAPIKey:     "SG.key",
+		EnableDebug:        true,
+		DigestOverdueAfter: 72 * time.Hour,
+	}.Redacted()
+
+	assert.Equal(t, "4000", values["PORT"])
+	assert.Equal(t, true, values["ENABLE_DEBUG"])
+	assert.Equal(t, "72h0m0s", values["DIGEST_OVERDUE_AFTER"])
+	assert.Equal(t, "[REDACTED]", values["JWT_SECRET"])
+	assert.Equal(t, "[REDACTED]", values["SENDGRID_API_KEY"])
+	assert.Equal(t, "", values["SMTP_CLIENT_SECRET"], "an unset secret stays empty")
+}
@@ -2,30 +2,86 @@ package core
 
 import (
 	"fmt"
+	"strconv"
 
+	v "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/gofiber/fiber/v2"
+
+	"github.com/mirusky-dev/challenge-18/core/i18n"
+	"github.com/mirusky-dev/challenge-18/core/metrics"
 )
 
 var ErrorHandler = func(ctx *fiber.Ctx, err error) error {
+	var exception *Exception
+
 	if e, ok := err.(*Exception); ok {
-		return ctx.Status(e.Status).JSON(e)
+		exception = e
 	} else if e, ok := err.(*fiber.Error); ok {
-		return ctx.Status(e.Code).JSON(
-			UserFriendlyException(
-				WithCode("framework-error"),
-				WithStatus(e.Code),
-				WithMessage(e.Message),
-			),
+		exception = UserFriendlyException(
+			WithCode(CodeFramework),
+			WithStatus(e.Code),
+			WithMessage(e.Message),
 		)
 	} else {
-		return ctx.Status(500).JSON(
-			Unexpected(
-				WithError(err),
-			),
+		exception = Unexpected(
+			WithError(err),
 		)
 	}
+
+	metrics.Exceptions.WithLabelValues(exception.Code, strconv.Itoa(exception.Status)).Inc()
+
+	exception = exception.Localize(RequestLocale(ctx))
+
+	// The Exception stays the default, the RFC 7807 shape must be asked for
+	if ctx.Accepts(fiber.MIMEApplicationJSON, MIMEApplicationProblemJSON) == MIMEApplicationProblemJSON {
+		var instance string
+		if appCtx, ok := FromContext(ctx.UserContext()); ok {
+			instance = appCtx.ID()
+		}
+
+		ctx.Status(exception.Status)
+		if err := ctx.JSON(exception.Problem(instance)); err != nil {
+			return err
+		}
+
+		ctx.Set(fiber.HeaderContentType, MIMEApplicationProblemJSON)
+		return nil
+	}
+
+	return ctx.Status(exception.Status).JSON(exception)
+}
+
+// Codes of the exceptions built by the constructors, each one has a default message in the i18n catalogs
+const (
+	CodeUserFriendly   = "user-friendly-exception"
+	CodeNotAllowed     = "not-allowed"
+	CodeNotImplemented = "not-implemented"
+	CodeNotFound       = "not-found"
+	CodeForbidden      = "forbidden"
+	CodeConflict       = "conflict"
+	CodeMissingContext = "missing-context"
+	CodeUnauthorized   = "unauthorized"
+	CodeBadRequest     = "bad-request"
+	CodeUnexpected     = "internal-server-error"
+)
+
+// Codes lists the codes with a default message
+var Codes = []string{
+	CodeUserFriendly,
+	CodeNotAllowed,
+	CodeNotImplemented,
+	CodeNotFound,
+	CodeForbidden,
+	CodeConflict,
+	CodeMissingContext,
+	CodeUnauthorized,
+	CodeBadRequest,
+	CodeUnexpected,
 }
 
+// framework-error carries the message of the framework, so it has no default message
+const CodeFramework = "framework-error"
+
 type Severity string
 
 const (
@@ -42,6 +98,15 @@ type Exception struct {
 	Message  string   `json:"message"`                      // User friendly message
 	Err      string   `json:"error,omitempty" form:"error"` // Golang error
 	Severity Severity `json:"severity"`                     // Exception level
+
+	Errors map[string]string `json:"errors,omitempty"` // Error of each invalid field
+
+	// validation keeps the rule errors, so Localize can translate them
+	validation v.Errors
+
+	// messageKey and messageParams keep the message given with WithMessageKey, so Localize can translate it
+	messageKey    string
+	messageParams map[string]any
 }
 
 func (e *Exception) Error() string {
@@ -62,9 +127,22 @@ func WithCode(code string) UserFriendlyExceptionOption {
 	}
 }
 
+// WithMessage sets a message that isn't translated, see WithMessageKey
 func WithMessage(message string) UserFriendlyExceptionOption {
 	return func(h *Exception) {
 		h.Message = message
+		h.messageKey = ""
+		h.messageParams = nil
+	}
+}
+
+// WithMessageKey sets the message of the key in the i18n catalogs (exception.<key>), it's translated to the locale of
+// the request by the ErrorHandler
+func WithMessageKey(key string, params map[string]any) UserFriendlyExceptionOption {
+	return func(h *Exception) {
+		h.Message = i18n.T(i18n.DefaultLocale, "exception."+key, params)
+		h.messageKey = key
+		h.messageParams = params
 	}
 }
 
@@ -86,7 +164,7 @@ func WithSeverity(severity Severity) UserFriendlyExceptionOption {
 
 func NotAllowed(opts ...UserFriendlyExceptionOption) *Exception {
 	defaultOpts := []UserFriendlyExceptionOption{
-		WithCode("not-allowed"),
+		WithCode(CodeNotAllowed),
 	}
 
 	defaultOpts = append(defaultOpts, opts...)
@@ -97,9 +175,9 @@ func NotAllowed(opts ...UserFriendlyExceptionOption) *Exception {
 func NotImplemented(opts ...UserFriendlyExceptionOption) *Exception {
 	defaultOpts := []UserFriendlyExceptionOption{
 		WithStatus(501),
-		WithCode("not-implemented"),
+		WithCode(CodeNotImplemented),
 		WithSeverity(Warn),
-		WithMessage("The current method was not implemented"),
+		WithMessage(defaultMessage(CodeNotImplemented)),
 	}
 
 	defaultOpts = append(defaultOpts, opts...)
@@ -110,9 +188,9 @@ func NotImplemented(opts ...UserFriendlyExceptionOption) *Exception {
 func NotFound(opts ...UserFriendlyExceptionOption) *Exception {
 	defaultOpts := []UserFriendlyExceptionOption{
 		WithStatus(404),
-		WithCode("not-found"),
+		WithCode(CodeNotFound),
 		WithSeverity(Info),
-		WithMessage("No entities found with given parameters"),
+		WithMessage(defaultMessage(CodeNotFound)),
 	}
 
 	defaultOpts = append(defaultOpts, opts...)
@@ -123,9 +201,23 @@ func NotFound(opts ...UserFriendlyExceptionOption) *Exception {
 func Forbidden(opts ...UserFriendlyExceptionOption) *Exception {
 	defaultOpts := []UserFriendlyExceptionOption{
 		WithStatus(403),
-		WithCode("forbidden"),
+		WithCode(CodeForbidden),
 		WithSeverity(Warn),
-		WithMessage("You don't have permission for that!"),
+		WithMessage(defaultMessage(CodeForbidden)),
+	}
+
+	defaultOpts = append(defaultOpts, opts...)
+
+	return UserFriendlyException(defaultOpts...)
+}
+
+// Conflict is returned when the request conflicts with the stored data, e.g: the username is already taken
+func Conflict(opts ...UserFriendlyExceptionOption) *Exception {
+	defaultOpts := []UserFriendlyExceptionOption{
+		WithStatus(409),
+		WithCode(CodeConflict),
+		WithSeverity(Info),
+		WithMessage(defaultMessage(CodeConflict)),
 	}
 
 	defaultOpts = append(defaultOpts, opts...)
@@ -135,7 +227,7 @@ func Forbidden(opts ...UserFriendlyExceptionOption) *Exception {
 
 func MissingContext(opts ...UserFriendlyExceptionOption) *Exception {
 	defaultOpts := []UserFriendlyExceptionOption{
-		WithCode("missing-context"),
+		WithCode(CodeMissingContext),
 	}
 
 	defaultOpts = append(defaultOpts, opts...)
@@ -146,9 +238,9 @@ func MissingContext(opts ...UserFriendlyExceptionOption) *Exception {
 func Unauthorized(opts ...UserFriendlyExceptionOption) *Exception {
 	defaultOpts := []UserFriendlyExceptionOption{
 		WithStatus(401),
-		WithCode("unauthorized"),
+		WithCode(CodeUnauthorized),
 		WithSeverity(Warn),
-		WithMessage("You need to login first"),
+		WithMessage(defaultMessage(CodeUnauthorized)),
 	}
 
 	defaultOpts = append(defaultOpts, opts...)
@@ -159,9 +251,9 @@ func Unauthorized(opts ...UserFriendlyExceptionOption) *Exception {
 func BadRequest(opts ...UserFriendlyExceptionOption) *Exception {
 	defaultOpts := []UserFriendlyExceptionOption{
 		WithStatus(400),
-		WithCode("bad-request"),
+		WithCode(CodeBadRequest),
 		WithSeverity(Info),
-		WithMessage("Ops, something is wrong in the request"),
+		WithMessage(defaultMessage(CodeBadRequest)),
 	}
 
 	defaultOpts = append(defaultOpts, opts...)
@@ -172,9 +264,9 @@ func BadRequest(opts ...UserFriendlyExceptionOption) *Exception {
 func Unexpected(opts ...UserFriendlyExceptionOption) *Exception {
 	defaultOpts := []UserFriendlyExceptionOption{
 		WithStatus(500),
-		WithCode("internal-server-error"),
+		WithCode(CodeUnexpected),
 		WithSeverity(Error),
-		WithMessage("Something went wrong"),
+		WithMessage(defaultMessage(CodeUnexpected)),
 	}
 
 	defaultOpts = append(defaultOpts, opts...)
@@ -185,14 +277,13 @@ func Unexpected(opts ...UserFriendlyExceptionOption) *Exception {
 func UserFriendlyException(opts ...UserFriendlyExceptionOption) *Exception {
 	const (
 		defaultStatus   = 500
-		defaultMessage  = "This is a friendly error, don't panic! Everything is under control"
-		defaultCode     = "user-friendly-exception"
+		defaultCode     = CodeUserFriendly
 		defaultSeverity = Info
 	)
 
 	h := &Exception{
 		Code:     defaultCode,
-		Message:  defaultMessage,
+		Message:  defaultMessage(defaultCode),
 		Status:   defaultStatus,
 		Severity: defaultSeverity,
 	}
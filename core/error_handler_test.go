@@ -0,0 +1,154 @@
+package core
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	v "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirusky-dev/challenge-18/core/i18n"
+)
+
+type signUp struct {
+	Email  string   `json:"email"`
+	Events []string `json:"events"`
+}
+
+func TestWithValidation(t *testing.T) {
+	input := signUp{Events: []string{"task.created", "nope"}}
+	err := v.ValidateStruct(&input,
+		v.Field(&input.Email, v.Required),
+		v.Field(&input.Events, v.Each(v.In("task.created"))),
+	)
+	require.Error(t, err)
+
+	exception := BadRequest(WithValidation(err))
+
+	assert.Equal(t, err.Error(), exception.Message)
+	assert.Equal(t, map[string]string{
+		"email":    "cannot be blank",
+		"events.1": "must be a valid value",
+	}, exception.Errors)
+
+	// A plain error has no fields
+	assert.Nil(t, BadRequest(WithValidation(assert.AnError)).Errors)
+}
+
+func TestCodesAreTranslated(t *testing.T) {
+	for _, code := range Codes {
+		for _, locale := range i18n.Locales() {
+			_, ok := i18n.Translate(locale, "exception."+code, nil)
+			assert.True(t, ok, "%s has no translation for %s", locale, code)
+		}
+	}
+}
+
+func TestMessageKeysAreTranslated(t *testing.T) {
+	for _, key := range MessageKeys {
+		for _, locale := range i18n.Locales() {
+			_, ok := i18n.Translate(locale, "exception."+key, nil)
+			assert.True(t, ok, "%s has no translation for %s", locale, key)
+		}
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	assert.Equal(t, "Nenhum registro encontrado com os parâmetros informados", NotFound().Localize("pt-BR").Message)
+	assert.Equal(t, "No entities found with given parameters", NotFound().Localize("de").Message)
+
+	// A custom message isn't in the catalogs
+	assert.Equal(t, "tasks are closed", BadRequest(WithMessage("tasks are closed")).Localize("pt-BR").Message)
+
+	// A message key is
+	exception := BadRequest(WithMessageKey(MessageManagersCantCreateTasks, nil))
+	assert.Equal(t, "managers can't create tasks", exception.Message)
+	assert.Equal(t, "gerentes não podem criar tarefas", exception.Localize("pt-BR").Message)
+	assert.Equal(t, "el usuario no tiene los roles requeridos: admin", HasRole("admin").Evaluate(&UserCtx{}).Localize("es").Message)
+
+	input := signUp{}
+	err := v.ValidateStruct(&input, v.Field(&input.Email, v.Required))
+	exception = BadRequest(WithValidation(err))
+
+	assert.Equal(t, "email: cannot be blank.", exception.Localize("en").Message)
+
+	localized := exception.Localize("es")
+	assert.Equal(t, "email: no puede estar vacío.", localized.Message)
+	assert.Equal(t, map[string]string{"email": "no puede estar vacío"}, localized.Errors)
+	assert.Equal(t, map[string]string{"email": "cannot be blank"}, exception.Errors, "the original exception is kept")
+}
+
+func TestErrorHandler(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.SetUserContext(NewContext(c.Context(), &UserCtx{ContextID: "ctx-1"}))
+		return c.Next()
+	})
+	app.Get("/", func(c *fiber.Ctx) error {
+		return BadRequest(WithMessage("invalid"), func(e *Exception) {
+			e.Errors = map[string]string{"email": "cannot be blank"}
+		})
+	})
+	app.Get("/not-found", func(c *fiber.Ctx) error {
+		return NotFound()
+	})
+
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+		want        string
+	}{
+		{
+			name:        "exception by default",
+			contentType: fiber.MIMEApplicationJSON,
+			want:        `{"status":400,"code":"bad-request","message":"invalid","severity":"info","errors":{"email":"cannot be blank"}}`,
+		},
+		{
+			name:        "exception for json",
+			accept:      "application/json, application/problem+json;q=0.9",
+			contentType: fiber.MIMEApplicationJSON,
+			want:        `{"status":400,"code":"bad-request","message":"invalid","severity":"info","errors":{"email":"cannot be blank"}}`,
+		},
+		{
+			name:        "problem when accepted",
+			accept:      "application/problem+json",
+			contentType: MIMEApplicationProblemJSON,
+			want:        `{"type":"/problems/bad-request","title":"Bad Request","status":400,"detail":"invalid","instance":"ctx-1","code":"bad-request","errors":{"email":"cannot be blank"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.accept != "" {
+				req.Header.Set(fiber.HeaderAccept, tt.accept)
+			}
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+
+			var body json.RawMessage
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+			assert.Equal(t, 400, resp.StatusCode)
+			assert.Equal(t, tt.contentType, resp.Header.Get(fiber.HeaderContentType))
+			assert.JSONEq(t, tt.want, string(body))
+		})
+	}
+
+	t.Run("localized by Accept-Language", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/not-found", nil)
+		req.Header.Set(fiber.HeaderAcceptLanguage, "pt-BR,pt;q=0.9")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		var body Exception
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, "Nenhum registro encontrado com os parâmetros informados", body.Message)
+	})
+}
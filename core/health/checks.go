@@ -0,0 +1,29 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+)
+
+// SQL pings the database
+func SQL(db *sql.DB) Check {
+	return db.PingContext
+}
+
+// Redis pings a Redis database
+func Redis(client *redis.Client) Check {
+	return func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	}
+}
+
+// Asynq lists the queues, it fails when the asynq Redis can't be reached
+func Asynq(inspector *asynq.Inspector) Check {
+	return func(ctx context.Context) error {
+		_, err := inspector.Queues()
+		return err
+	}
+}
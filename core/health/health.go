@@ -0,0 +1,237 @@
+// Package health checks the dependencies of the API and the background worker for the liveness, readiness and
+// status endpoints.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mirusky-dev/challenge-18/core/env"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Check returns an error when the dependency isn't usable, it must honor the ctx deadline
+type Check func(ctx context.Context) error
+
+// Result is the outcome of a check
+type Result struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report is the outcome of every check, it's up when all of them are
+type Report struct {
+	Status    string            `json:"status"`
+	CheckedAt time.Time         `json:"checkedAt"`
+	Checks    map[string]Result `json:"checks"`
+}
+
+// Redacted returns a copy of the report without the errors
+func (r Report) Redacted() Report {
+	checks := make(map[string]Result, len(r.Checks))
+	for name, result := range r.Checks {
+		result.Error = ""
+		checks[name] = result
+	}
+
+	r.Checks = checks
+	return r
+}
+
+// Build describes the running binary, the values are set by ldflags
+type Build struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"buildTime"`
+}
+
+// Status is the Report plus what is running
+type Status struct {
+	Report
+	Service   string    `json:"service"`
+	Build     Build     `json:"build"`
+	StartedAt time.Time `json:"startedAt"`
+	Uptime    string    `json:"uptime"`
+}
+
+func BuildInfo() Build {
+	return Build{
+		Version:   env.VERSION,
+		Commit:    env.SHORT_COMMIT,
+		BuildTime: env.BUILD_TIME,
+	}
+}
+
+type named struct {
+	name  string
+	check Check
+}
+
+// Checker runs the checks concurrently, each one with a timeout. The report is cached for the ttl, so a probe
+// hitting it often doesn't load the dependencies.
+type Checker struct {
+	service   string
+	timeout   time.Duration
+	ttl       time.Duration
+	startedAt time.Time
+
+	checks []named
+
+	mu        sync.Mutex
+	last      Report
+	expiresAt time.Time
+}
+
+func NewChecker(service string, timeout, ttl time.Duration) *Checker {
+	return &Checker{
+		service:   service,
+		timeout:   timeout,
+		ttl:       ttl,
+		startedAt: time.Now(),
+	}
+}
+
+// Add registers a check, it must be done before serving
+func (c *Checker) Add(name string, check Check) *Checker {
+	c.checks = append(c.checks, named{name: name, check: check})
+	return c
+}
+
+// Check returns the cached report, running the checks when it expired
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Now().Before(c.expiresAt) {
+		return c.last
+	}
+
+	// The report is shared, a probe giving up doesn't fail the checks of the others
+	c.last = c.run(context.WithoutCancel(ctx))
+	c.expiresAt = time.Now().Add(c.ttl)
+
+	return c.last
+}
+
+func (c *Checker) run(ctx context.Context) Report {
+	results := make([]Result, len(c.checks))
+
+	var wg sync.WaitGroup
+	for i, n := range c.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = c.runOne(ctx, check)
+		}(i, n.check)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status:    StatusUp,
+		CheckedAt: time.Now().UTC(),
+		Checks:    make(map[string]Result, len(c.checks)),
+	}
+
+	for i, n := range c.checks {
+		report.Checks[n.name] = results[i]
+		if results[i].Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+
+	return report
+}
+
+func (c *Checker) runOne(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	// A check that ignores the deadline doesn't hold the report
+	done := make(chan error, 1)
+	go func() { done <- check(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := Result{Status: StatusUp, Duration: time.Since(start).Round(time.Microsecond).String()}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// Status returns the report with the build info and the uptime
+func (c *Checker) Status(ctx context.Context) Status {
+	return Status{
+		Report:    c.Check(ctx),
+		Service:   c.service,
+		Build:     BuildInfo(),
+		StartedAt: c.startedAt.UTC(),
+		Uptime:    time.Since(c.startedAt).Round(time.Second).String(),
+	}
+}
+
+// Register adds /healthz, /readyz and /status to the mux
+func (c *Checker) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/healthz", c.Liveness)
+	mux.HandleFunc("/readyz", c.Readiness)
+	mux.HandleFunc("/status", c.StatusHandler)
+}
+
+// Liveness answers as long as the process can serve, it doesn't check the dependencies, restarting the process
+// wouldn't fix them
+func (c *Checker) Liveness(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": StatusUp})
+}
+
+// Readiness answers 503 when a dependency is down, so no traffic is routed to the process
+func (c *Checker) Readiness(w http.ResponseWriter, r *http.Request) {
+	report := c.Check(r.Context())
+
+	status := http.StatusOK
+	if report.Status != StatusUp {
+		status = http.StatusServiceUnavailable
+	}
+
+	writeJSON(w, status, report)
+}
+
+// PublicReadiness is Readiness without the errors of the checks, they can name the hosts of the dependencies
+func (c *Checker) PublicReadiness(w http.ResponseWriter, r *http.Request) {
+	report := c.Check(r.Context()).Redacted()
+
+	status := http.StatusOK
+	if report.Status != StatusUp {
+		status = http.StatusServiceUnavailable
+	}
+
+	writeJSON(w, status, report)
+}
+
+// StatusHandler answers the Status, it's always 200 since it's meant for people and dashboards
+func (c *Checker) StatusHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, c.Status(r.Context()))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,101 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func up(ctx context.Context) error { return nil }
+
+func TestChecker(t *testing.T) {
+	checker := NewChecker("test", 50*time.Millisecond, time.Minute).
+		Add("mysql", up).
+		Add("redis", func(ctx context.Context) error { return errors.New("connection refused") }).
+		Add("slow", func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		})
+
+	start := time.Now()
+	report := checker.Check(context.Background())
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "a check ignoring the deadline shouldn't hold the report")
+
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, StatusUp, report.Checks["mysql"].Status)
+	assert.Equal(t, "connection refused", report.Checks["redis"].Error)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+}
+
+func TestCheckerCache(t *testing.T) {
+	var calls atomic.Int32
+	checker := NewChecker("test", time.Second, time.Minute).Add("counted", func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+
+	checker.Check(context.Background())
+	checker.Check(context.Background())
+	assert.Equal(t, int32(1), calls.Load())
+
+	checker.expiresAt = time.Time{}
+	checker.Check(context.Background())
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestHandlers(t *testing.T) {
+	fail := true
+	checker := NewChecker("test", time.Second, 0).Add("mysql", func(ctx context.Context) error {
+		if fail {
+			return errors.New("down")
+		}
+		return nil
+	})
+
+	mux := http.NewServeMux()
+	checker.Register(mux)
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/healthz").Code, "liveness doesn't depend on the checks")
+	assert.Equal(t, http.StatusServiceUnavailable, serve("/readyz").Code)
+
+	fail = false
+	assert.Equal(t, http.StatusOK, serve("/readyz").Code)
+
+	var status Status
+	rec := serve("/status")
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	assert.Equal(t, "test", status.Service)
+	assert.Equal(t, StatusUp, status.Status)
+	assert.Contains(t, status.Checks, "mysql")
+}
+
+func TestPublicReadiness(t *testing.T) {
+	checker := NewChecker("test", time.Second, time.Minute).
+		Add("mysql", func(ctx context.Context) error { return errors.New("dial tcp 10.0.0.12:3306: connection refused") })
+
+	rec := httptest.NewRecorder()
+	checker.PublicReadiness(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var report Report
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, StatusDown, report.Checks["mysql"].Status)
+	assert.Empty(t, report.Checks["mysql"].Error)
+
+	// The cached report keeps the error for the internal endpoints
+	assert.NotEmpty(t, checker.Check(context.Background()).Checks["mysql"].Error)
+}
@@ -0,0 +1,211 @@
+// Package i18n holds the message catalogs of the API, the exceptions and validation rules are translated by their codes.
+//
+// A catalog is a flat JSON file per locale (locales/<locale>.json), the messages are text/template strings.
+package i18n
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+//go:embed locales
+var embeddedLocales embed.FS
+
+// DefaultLocale is the last fallback, every key must exist in its catalog
+const DefaultLocale = "en"
+
+var catalogs = mustLoad(embeddedLocales)
+
+func mustLoad(fsys fs.FS) map[string]map[string]string {
+	files, err := fs.Glob(fsys, "locales/*.json")
+	if err != nil {
+		panic(err)
+	}
+
+	loaded := map[string]map[string]string{}
+	for _, file := range files {
+		b, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			panic(err)
+		}
+
+		messages := map[string]string{}
+		if err := json.Unmarshal(b, &messages); err != nil {
+			panic(file + ": " + err.Error())
+		}
+
+		loaded[strings.TrimSuffix(path.Base(file), ".json")] = messages
+	}
+
+	return loaded
+}
+
+// Locales returns the locales with a catalog
+func Locales() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	return locales
+}
+
+// Keys returns the keys of the default catalog
+func Keys() []string {
+	keys := make([]string, 0, len(catalogs[DefaultLocale]))
+	for key := range catalogs[DefaultLocale] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Candidates returns the fallback chain of a locale, e.g: pt-BR -> pt -> en
+func Candidates(locale string) []string {
+	var candidates []string
+	if locale != "" {
+		candidates = append(candidates, locale)
+		if base, _, found := strings.Cut(locale, "-"); found {
+			candidates = append(candidates, base)
+		}
+	}
+
+	if locale != DefaultLocale {
+		candidates = append(candidates, DefaultLocale)
+	}
+
+	return candidates
+}
+
+// Translate returns the message of the key in the closest locale, false when no catalog has it
+func Translate(locale, key string, params map[string]any) (string, bool) {
+	for _, candidate := range Candidates(locale) {
+		message, ok := catalogs[candidate][key]
+		if !ok {
+			continue
+		}
+
+		if !strings.Contains(message, "{{") {
+			return message, true
+		}
+
+		tmpl, err := template.New(key).Option("missingkey=zero").Parse(message)
+		if err != nil {
+			return message, true
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, params); err != nil {
+			return message, true
+		}
+
+		return buf.String(), true
+	}
+
+	return "", false
+}
+
+// T is Translate returning the key itself when it's missing, handy within templates
+func T(locale, key string, params map[string]any) string {
+	if message, ok := Translate(locale, key, params); ok {
+		return message
+	}
+
+	return key
+}
+
+// Missing returns the keys of the default catalog without a translation, by locale
+func Missing() map[string][]string {
+	missing := map[string][]string{}
+
+	for _, locale := range Locales() {
+		for _, key := range Keys() {
+			if _, ok := catalogs[locale][key]; !ok {
+				missing[locale] = append(missing[locale], key)
+			}
+		}
+	}
+
+	return missing
+}
+
+// Match returns the supported locale that best fits an Accept-Language header, empty when none does
+func Match(acceptLanguage string) string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+
+		if q > 0 {
+			tags = append(tags, weighted{tag, q})
+		}
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	for _, tag := range tags {
+		if locale := supported(tag.tag); locale != "" {
+			return locale
+		}
+	}
+
+	return ""
+}
+
+// supported matches a language tag case insensitively, first exactly (pt-br -> pt-BR), then by its base (pt-PT -> pt-BR)
+func supported(tag string) string {
+	locales := Locales()
+
+	for _, locale := range locales {
+		if strings.EqualFold(locale, tag) {
+			return locale
+		}
+	}
+
+	base, _, _ := strings.Cut(tag, "-")
+	for _, locale := range locales {
+		localeBase, _, _ := strings.Cut(locale, "-")
+		if strings.EqualFold(localeBase, base) {
+			return locale
+		}
+	}
+
+	return ""
+}
+
+type localeKey struct{}
+
+// NewContext returns a copy of parent with the locale of the request
+func NewContext(parent context.Context, locale string) context.Context {
+	return context.WithValue(parent, localeKey{}, locale)
+}
+
+// FromContext returns the locale of the request
+func FromContext(ctx context.Context) (string, bool) {
+	locale, ok := ctx.Value(localeKey{}).(string)
+	return locale, ok
+}
@@ -0,0 +1,110 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+
+	v "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogsAreComplete(t *testing.T) {
+	assert.Contains(t, Locales(), DefaultLocale)
+	assert.Empty(t, Missing(), "every key of the default catalog must be translated")
+}
+
+// The default catalog keeps the messages of ozzo-validation, so the English errors don't change
+func TestValidationMessages(t *testing.T) {
+	rules := []v.Error{
+		v.ErrRequired,
+		v.ErrNilOrNotEmpty,
+		v.ErrNotNilRequired,
+		v.ErrNil,
+		v.ErrEmpty,
+		v.ErrInInvalid,
+		v.ErrNotInInvalid,
+		v.ErrMatchInvalid,
+		v.ErrLengthTooLong,
+		v.ErrLengthTooShort,
+		v.ErrLengthInvalid,
+		v.ErrLengthOutOfRange,
+		v.ErrLengthEmptyRequired,
+		is.ErrEmail,
+		is.ErrURL,
+		is.ErrUUID,
+	}
+
+	for _, rule := range rules {
+		t.Run(rule.Code(), func(t *testing.T) {
+			assert.Equal(t, rule.Message(), catalogs[DefaultLocale]["validation."+rule.Code()])
+		})
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	params := map[string]any{"min": 16, "max": 64}
+
+	tests := []struct {
+		name   string
+		locale string
+		key    string
+		want   string
+		wantOk bool
+	}{
+		{"exact locale", "pt-BR", "validation.validation_required", "não pode ficar em branco", true},
+		{"params", "es", "validation.validation_length_out_of_range", "la longitud debe estar entre 16 y 64", true},
+		{"falls back to default", "de-DE", "validation.validation_required", "cannot be blank", true},
+		{"no locale", "", "exception.not-found", "No entities found with given parameters", true},
+		{"missing key", "pt-BR", "unknown", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Translate(tt.locale, tt.key, params)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	assert.Equal(t, "unknown", T("en", "unknown", nil))
+}
+
+func TestCandidates(t *testing.T) {
+	assert.Equal(t, []string{"pt-BR", "pt", "en"}, Candidates("pt-BR"))
+	assert.Equal(t, []string{"es", "en"}, Candidates("es"))
+	assert.Equal(t, []string{"en"}, Candidates("en"))
+	assert.Equal(t, []string{"en"}, Candidates(""))
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		want           string
+	}{
+		{"", ""},
+		{"*", ""},
+		{"de-DE", ""},
+		{"pt-BR", "pt-BR"},
+		{"pt-br", "pt-BR"},
+		{"pt-PT,pt;q=0.9", "pt-BR"},
+		{"es-AR", "es"},
+		{"de-DE,es;q=0.5,en;q=0.8", "en"},
+		{"en;q=0,es", "es"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			assert.Equal(t, tt.want, Match(tt.acceptLanguage))
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+
+	locale, ok := FromContext(NewContext(context.Background(), "es"))
+	assert.True(t, ok)
+	assert.Equal(t, "es", locale)
+}
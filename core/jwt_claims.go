@@ -6,5 +6,6 @@ import (
 
 type Claims struct {
 	jwt.RegisteredClaims
-	Role string `json:"role"`
+	Role   string `json:"role"`
+	Locale string `json:"locale,omitempty"` // localizes the errors when the request has no Accept-Language
 }
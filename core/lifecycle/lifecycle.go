@@ -0,0 +1,158 @@
+// Package lifecycle owns the resources of a process: it starts them in the order they were added and stops them in
+// reverse, so a server stops taking requests before the database it uses is closed.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Hook is a resource of the process, both funcs are optional.
+//
+// A hook without OnStart is a resource that's already open, e.g: a database pool, it's stopped even when Start wasn't
+// called.
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+type entry struct {
+	Hook
+	started bool
+}
+
+// Lifecycle runs the hooks, each OnStop has its own timeout within the deadline of Stop
+type Lifecycle struct {
+	logger  *slog.Logger
+	timeout time.Duration
+
+	mu    sync.Mutex
+	hooks []*entry
+}
+
+func New(logger *slog.Logger, timeout time.Duration) *Lifecycle {
+	return &Lifecycle{
+		logger:  logger,
+		timeout: timeout,
+	}
+}
+
+// Append adds a hook, it must be done before Start
+func (l *Lifecycle) Append(hook Hook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hooks = append(l.hooks, &entry{Hook: hook, started: hook.OnStart == nil})
+}
+
+// AppendCloser adds an open resource, it's closed on Stop
+func (l *Lifecycle) AppendCloser(name string, closer io.Closer) {
+	l.Append(Hook{
+		Name:   name,
+		OnStop: func(ctx context.Context) error { return closer.Close() },
+	})
+}
+
+// Start runs the OnStart of the hooks in order, stopping at the first error. The caller must Stop anyway, to release
+// what was started.
+func (l *Lifecycle) Start(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, hook := range l.hooks {
+		if hook.started {
+			continue
+		}
+
+		if err := hook.OnStart(ctx); err != nil {
+			return fmt.Errorf("starting %s: %w", hook.Name, err)
+		}
+
+		hook.started = true
+		l.logger.DebugContext(ctx, "lifecycle: started", "hook", hook.Name)
+	}
+
+	return nil
+}
+
+// Stop runs the OnStop of the started hooks in reverse. A hook that doesn't return in time is abandoned, so the next
+// ones still get to stop.
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var errs []error
+	for i := len(l.hooks) - 1; i >= 0; i-- {
+		hook := l.hooks[i]
+		if !hook.started || hook.OnStop == nil {
+			continue
+		}
+
+		start := time.Now()
+		err := l.stop(ctx, hook.Hook)
+		hook.started = false
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("stopping %s: %w", hook.Name, err))
+			l.logger.ErrorContext(ctx, "lifecycle: stop failed", "hook", hook.Name, "error", err)
+			continue
+		}
+
+		l.logger.DebugContext(ctx, "lifecycle: stopped", "hook", hook.Name, "duration", time.Since(start))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (l *Lifecycle) stop(ctx context.Context, hook Hook) error {
+	ctx, cancel := context.WithTimeout(ctx, l.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- hook.OnStop(ctx) }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Run starts the hooks and blocks until the ctx is done or an interrupt or termination signal is received, then
+// stops them within the timeout
+func (l *Lifecycle) Run(ctx context.Context, timeout time.Duration) error {
+	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	err := l.Start(ctx)
+	if err == nil {
+		<-ctx.Done()
+		l.logger.Info("lifecycle: shutting down")
+	}
+
+	stopCtx, stopCancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer stopCancel()
+
+	return errors.Join(err, l.Stop(stopCtx))
+}
+
+// Timeout returns the time left before the ctx deadline, 0 when there's none. It's for the APIs taking a duration
+// instead of a context.
+func Timeout(ctx context.Context) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0
+	}
+
+	return time.Until(deadline)
+}
@@ -0,0 +1,69 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLifecycle(t *testing.T) {
+	var calls []string
+	hook := func(name string) Hook {
+		return Hook{
+			Name:    name,
+			OnStart: func(ctx context.Context) error { calls = append(calls, "start "+name); return nil },
+			OnStop:  func(ctx context.Context) error { calls = append(calls, "stop "+name); return nil },
+		}
+	}
+
+	lc := New(slog.New(slog.NewTextHandler(io.Discard, nil)), time.Second)
+	lc.Append(Hook{Name: "db", OnStop: func(ctx context.Context) error { calls = append(calls, "stop db"); return nil }})
+	lc.Append(hook("worker"))
+	lc.Append(hook("server"))
+
+	require.NoError(t, lc.Start(context.Background()))
+	require.NoError(t, lc.Stop(context.Background()))
+
+	assert.Equal(t, []string{"start worker", "start server", "stop server", "stop worker", "stop db"}, calls)
+}
+
+func TestLifecycleStartFailure(t *testing.T) {
+	var stopped []string
+	stop := func(name string) func(ctx context.Context) error {
+		return func(ctx context.Context) error { stopped = append(stopped, name); return nil }
+	}
+
+	lc := New(slog.New(slog.NewTextHandler(io.Discard, nil)), time.Second)
+	lc.Append(Hook{Name: "db", OnStop: stop("db")})
+	lc.Append(Hook{Name: "worker", OnStart: func(ctx context.Context) error { return errors.New("port taken") }, OnStop: stop("worker")})
+	lc.Append(Hook{Name: "cache", OnStop: stop("cache")})
+
+	assert.ErrorContains(t, lc.Start(context.Background()), "starting worker: port taken")
+	require.NoError(t, lc.Stop(context.Background()))
+
+	assert.Equal(t, []string{"cache", "db"}, stopped, "the open resources are closed, the hook that didn't start isn't stopped")
+}
+
+func TestLifecycleStopTimeout(t *testing.T) {
+	var closed bool
+
+	lc := New(slog.New(slog.NewTextHandler(io.Discard, nil)), 50*time.Millisecond)
+	lc.Append(Hook{Name: "db", OnStop: func(ctx context.Context) error { closed = true; return nil }})
+	lc.Append(Hook{Name: "stuck", OnStop: func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}})
+
+	start := time.Now()
+	err := lc.Stop(context.Background())
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "a hook ignoring the deadline shouldn't hold the others")
+	assert.True(t, closed)
+}
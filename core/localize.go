@@ -0,0 +1,64 @@
+package core
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/exp/maps"
+
+	"github.com/mirusky-dev/challenge-18/core/i18n"
+)
+
+func defaultMessage(code string) string {
+	return i18n.T(i18n.DefaultLocale, "exception."+code, nil)
+}
+
+// Localize returns a copy of the exception in the locale.
+//
+// The default messages of the codes, the messages given with WithMessageKey and the validation errors are translated, a
+// message given with WithMessage is kept as is.
+func (e *Exception) Localize(locale string) *Exception {
+	localized := *e
+
+	if e.messageKey != "" {
+		localized.Message = i18n.T(locale, "exception."+e.messageKey, e.messageParams)
+	} else if e.Message == defaultMessage(e.Code) {
+		localized.Message = i18n.T(locale, "exception."+e.Code, nil)
+	}
+
+	if e.validation != nil {
+		localized.Errors = map[string]string{}
+		fieldErrors(localized.Errors, "", e.validation, locale)
+
+		// Nothing was translated, keeps the original message
+		if maps.Equal(localized.Errors, e.Errors) {
+			return &localized
+		}
+
+		// Same format as ozzo-validation, e.g: "email: cannot be blank; password: cannot be blank."
+		fields := make([]string, 0, len(localized.Errors))
+		for field, message := range localized.Errors {
+			fields = append(fields, field+": "+message)
+		}
+		sort.Strings(fields)
+
+		localized.Message = strings.Join(fields, "; ") + "."
+	}
+
+	return &localized
+}
+
+// RequestLocale returns the locale of the request: the one stored in its context (Accept-Language or the user's locale),
+// else the best match of the Accept-Language header, else the default locale
+func RequestLocale(c *fiber.Ctx) string {
+	if locale, ok := i18n.FromContext(c.UserContext()); ok && locale != "" {
+		return locale
+	}
+
+	if locale := i18n.Match(c.Get(fiber.HeaderAcceptLanguage)); locale != "" {
+		return locale
+	}
+
+	return i18n.DefaultLocale
+}
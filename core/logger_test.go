@@ -0,0 +1,52 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(&buf, "info", LogFormatJSON)
+	require.NoError(t, err)
+
+	appCtx := NewUserCtx("user-1", []string{"tech"}, []string{})
+	ctx := NewContext(context.Background(), appCtx)
+
+	logger.DebugContext(ctx, "hidden")
+	logger.With("component", "test").InfoContext(ctx, "request", "status", 200)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+
+	assert.Equal(t, "request", record["msg"])
+	assert.Equal(t, "test", record["component"])
+	assert.Equal(t, appCtx.ID(), record["contextId"])
+	assert.Equal(t, "user-1", record["userId"])
+}
+
+func TestNewLoggerInvalid(t *testing.T) {
+	_, err := NewLogger(&bytes.Buffer{}, "verbose", LogFormatJSON)
+	assert.Error(t, err)
+
+	_, err = NewLogger(&bytes.Buffer{}, "info", "xml")
+	assert.Error(t, err)
+}
+
+func TestCorrelationID(t *testing.T) {
+	assert.Empty(t, CorrelationID(context.Background()))
+
+	appCtx := NewUserCtx("", []string{}, []string{})
+	ctx := NewContext(context.Background(), appCtx)
+	assert.Equal(t, appCtx.ID(), CorrelationID(ctx))
+
+	// The worker keeps the request ID even when a handler creates its own Ctx
+	ctx = WithCorrelationID(context.Background(), "req-1")
+	ctx = NewContext(ctx, appCtx)
+	assert.Equal(t, "req-1", CorrelationID(ctx))
+}
@@ -0,0 +1,43 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/core/env"
+)
+
+// fileMailer writes every mail as an .eml file, which can be opened by any mail client
+type fileMailer struct {
+	dir   string
+	clock core.Clock
+	ids   core.IDGenerator
+}
+
+func NewFileMailer(config env.Config, clock core.Clock, ids core.IDGenerator) (Mailer, error) {
+	if err := os.MkdirAll(config.MailerFileDir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &fileMailer{
+		dir:   config.MailerFileDir,
+		clock: clock,
+		ids:   ids,
+	}, nil
+}
+
+func (mailer *fileMailer) Send(email Mail) error {
+	now := mailer.clock.Now()
+
+	b, err := email.MIME(now)
+	if err != nil {
+		return err
+	}
+
+	// Prefixed by date, so `ls` shows them in the order they were sent
+	filename := fmt.Sprintf("%s-%s.eml", now.Format("20060102T150405.000"), mailer.ids.NewID())
+
+	return os.WriteFile(path.Join(mailer.dir, filename), b, 0o600)
+}
@@ -1,20 +1,185 @@
 package mailer
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/textproto"
+	"path"
+	"strings"
+
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/core/env"
+	"github.com/mirusky-dev/challenge-18/core/metrics"
+)
+
+const (
+	DriverNoop     = "noop"
+	DriverSMTP     = "smtp"
+	DriverSendgrid = "sendgrid"
+	DriverFile     = "file"
+	DriverMemory   = "memory"
+)
+
 type EmailInfo struct {
 	Name  string
 	Email string
 }
 
+// Attachment is a file sent along the mail.
+//
+// When ContentID is set the attachment is inline and can be referenced in the HTML as <img src="cid:ContentID">.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+	ContentID   string
+}
+
+func (a Attachment) Inline() bool {
+	return a.ContentID != ""
+}
+
 type Mail struct {
 	From      EmailInfo
+	ReplyTo   *EmailInfo
 	To        []EmailInfo
 	Cc        []EmailInfo
 	Bcc       []EmailInfo
 	Subject   string
 	PlainText string
 	HTML      string
+
+	// ListUnsubscribe is the List-Unsubscribe header value, e.g: <mailto:unsubscribe@example.com>, <https://example.com/unsubscribe>
+	ListUnsubscribe string
+	// Headers are extra headers, the ones controlled by the Mail fields (From, To, Subject...) can't be set here
+	Headers     map[string]string
+	Attachments []Attachment
+}
+
+// reservedHeaders are set from the Mail fields, drivers ignore them in Mail.Headers
+var reservedHeaders = map[string]bool{
+	"From":                      true,
+	"Reply-To":                  true,
+	"To":                        true,
+	"Cc":                        true,
+	"Bcc":                       true,
+	"Subject":                   true,
+	"Date":                      true,
+	"Message-Id":                true,
+	"Mime-Version":              true,
+	"Content-Type":              true,
+	"Content-Transfer-Encoding": true,
+	"List-Unsubscribe":          true,
+}
+
+var ErrReservedHeader = errors.New("reserved header")
+
+// Validate checks the mail can be sent the same way by every driver
+func (m Mail) Validate() error {
+	for key := range m.Headers {
+		if reservedHeaders[textproto.CanonicalMIMEHeaderKey(key)] {
+			return fmt.Errorf("%w: %s must be set through the Mail fields", ErrReservedHeader, key)
+		}
+	}
+
+	for _, attachment := range m.Attachments {
+		if attachment.Filename == "" {
+			return errors.New("attachment without filename")
+		}
+	}
+
+	return nil
+}
+
+// contentType returns the attachment content type, guessing it from the filename when missing
+func (a Attachment) contentType() string {
+	if a.ContentType != "" {
+		return a.ContentType
+	}
+
+	if contentType := mime.TypeByExtension(path.Ext(a.Filename)); contentType != "" {
+		return contentType
+	}
+
+	return "application/octet-stream"
 }
 
 type Mailer interface {
 	Send(Mail) error
 }
+
+// ContextMailer is a Mailer that can carry the request context, e.g: to correlate a queued mail with its request
+type ContextMailer interface {
+	Mailer
+	SendContext(context.Context, Mail) error
+}
+
+// SendContext sends with the context when the mailer supports it
+func SendContext(ctx context.Context, m Mailer, mail Mail) error {
+	if cm, ok := m.(ContextMailer); ok {
+		return cm.SendContext(ctx, mail)
+	}
+
+	return m.Send(mail)
+}
+
+// Close releases the connections of the mailer, when it holds some
+func Close(m Mailer) error {
+	if closer, ok := m.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// New creates the mailer selected by config.Mailer, its sends are counted by driver and outcome.
+// The drivers that name or date the mails themselves use clock and ids
+func New(config env.Config, clock core.Clock, ids core.IDGenerator) (Mailer, error) {
+	driver := strings.ToLower(config.Mailer)
+	if driver == "" {
+		driver = DriverNoop
+	}
+
+	var m Mailer
+	var err error
+
+	switch driver {
+	case DriverNoop:
+		m, err = NewNoopMailer(config)
+	case DriverSMTP:
+		m, err = NewSimpleMailMailer(config)
+	case DriverSendgrid:
+		m, err = NewSendgridMailer(config)
+	case DriverFile:
+		m, err = NewFileMailer(config, clock, ids)
+	case DriverMemory:
+		m = NewMemoryMailer()
+	default:
+		return nil, fmt.Errorf("unknown mailer driver %q", config.Mailer)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return instrumentedMailer{Mailer: m, driver: driver}, nil
+}
+
+// instrumentedMailer counts the outcome of the sends in metrics.MailsSent
+type instrumentedMailer struct {
+	Mailer
+	driver string
+}
+
+func (m instrumentedMailer) Close() error {
+	return Close(m.Mailer)
+}
+
+func (m instrumentedMailer) Send(mail Mail) error {
+	err := m.Mailer.Send(mail)
+	metrics.MailsSent.WithLabelValues(m.driver, metrics.Outcome(err)).Inc()
+	return err
+}
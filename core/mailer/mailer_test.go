@@ -0,0 +1,206 @@
+package mailer
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/core/env"
+	"github.com/mirusky-dev/challenge-18/core/metrics"
+)
+
+func sampleMail() Mail {
+	return Mail{
+		From:      EmailInfo{Name: "No Reply", Email: "no-reply@example.com"},
+		To:        []EmailInfo{{Name: "Jane", Email: "jane@example.com"}},
+		Cc:        []EmailInfo{{Name: "Manager", Email: "manager@example.com"}},
+		Subject:   "Tarefa concluída",
+		PlainText: "plain body",
+		HTML:      "<p>html body</p>",
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		driver  string
+		wantErr bool
+	}{
+		{name: "default", driver: ""},
+		{name: "noop", driver: DriverNoop},
+		{name: "file", driver: DriverFile},
+		{name: "memory", driver: "MEMORY"},
+		{name: "unknown", driver: "pigeon", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(env.Config{Mailer: tt.driver, MailerFileDir: t.TempDir()}, core.NewSystemClock(), core.NewUUIDv7Generator())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewCountsSends(t *testing.T) {
+	mailer, err := New(env.Config{Mailer: DriverMemory}, core.NewSystemClock(), core.NewUUIDv7Generator())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent := testutil.ToFloat64(metrics.MailsSent.WithLabelValues(DriverMemory, metrics.OutcomeSuccess))
+	failed := testutil.ToFloat64(metrics.MailsSent.WithLabelValues(DriverMemory, metrics.OutcomeFailure))
+
+	_ = mailer.Send(sampleMail())
+	_ = mailer.Send(Mail{Headers: map[string]string{"Subject": "reserved"}})
+
+	if got := testutil.ToFloat64(metrics.MailsSent.WithLabelValues(DriverMemory, metrics.OutcomeSuccess)); got != sent+1 {
+		t.Errorf("sent = %v, want %v", got, sent+1)
+	}
+
+	if got := testutil.ToFloat64(metrics.MailsSent.WithLabelValues(DriverMemory, metrics.OutcomeFailure)); got != failed+1 {
+		t.Errorf("failed = %v, want %v", got, failed+1)
+	}
+}
+
+func TestMemoryMailer(t *testing.T) {
+	mailer := NewMemoryMailer()
+
+	if _, ok := mailer.Last(); ok {
+		t.Fatal("MemoryMailer.Last() should be empty")
+	}
+
+	if err := mailer.Send(sampleMail()); err != nil {
+		t.Fatal(err)
+	}
+
+	if mailer.Len() != 1 {
+		t.Errorf("MemoryMailer.Len() = %d, want 1", mailer.Len())
+	}
+
+	if got := mailer.To("MANAGER@example.com"); len(got) != 1 {
+		t.Errorf("MemoryMailer.To() = %d mails, want 1", len(got))
+	}
+
+	if got := mailer.To("someone@example.com"); len(got) != 0 {
+		t.Errorf("MemoryMailer.To() = %d mails, want 0", len(got))
+	}
+
+	if last, _ := mailer.Last(); last.Subject != "Tarefa concluída" {
+		t.Errorf("MemoryMailer.Last() subject = %q", last.Subject)
+	}
+
+	mailer.Reset()
+	if mailer.Len() != 0 {
+		t.Errorf("MemoryMailer.Len() = %d after Reset, want 0", mailer.Len())
+	}
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := path.Join(t.TempDir(), "mails")
+
+	mailer, err := NewFileMailer(env.Config{MailerFileDir: dir}, core.NewSystemClock(), core.NewUUIDv7Generator())
+	if err != nil {
+		t.Fatal("precondition failed: NewFileMailer() error = ", err)
+	}
+
+	if err := mailer.Send(sampleMail()); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 || !strings.HasSuffix(entries[0].Name(), ".eml") {
+		t.Fatalf("expected a single .eml file, got %v (err %v)", entries, err)
+	}
+
+	b, err := os.ReadFile(path.Join(dir, entries[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	eml := string(b)
+	for _, want := range []string{
+		`From: "No Reply" <no-reply@example.com>`,
+		`To: "Jane" <jane@example.com>`,
+		"Subject: =?utf-8?q?Tarefa_conclu=C3=ADda?=",
+		"multipart/alternative",
+		"plain body",
+		"<p>html body</p>",
+	} {
+		if !strings.Contains(eml, want) {
+			t.Errorf("eml doesn't contain %q:\n%s", want, eml)
+		}
+	}
+}
+
+func TestMail_MIME_SinglePart(t *testing.T) {
+	mail := sampleMail()
+	mail.HTML = ""
+
+	b, err := mail.MIME(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if eml := string(b); strings.Contains(eml, "multipart") || !strings.HasSuffix(eml, "plain body") {
+		t.Errorf("expected a single text/plain part:\n%s", eml)
+	}
+}
+
+func TestMail_MIME_Attachments(t *testing.T) {
+	mail := sampleMail()
+	mail.ReplyTo = &EmailInfo{Name: "Support", Email: "support@example.com"}
+	mail.ListUnsubscribe = "<mailto:unsubscribe@example.com>"
+	mail.Headers = map[string]string{"X-Campaign": "tasks"}
+	mail.HTML = `<img src="cid:logo.png"><p>html body</p>`
+	mail.Attachments = []Attachment{
+		{Filename: "logo.png", Data: []byte("png"), ContentID: "logo.png"},
+		{Filename: "tasks.csv", ContentType: "text/csv", Data: []byte("id,summary\n")},
+	}
+
+	b, err := mail.MIME(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	eml := string(b)
+	for _, want := range []string{
+		`Reply-To: "Support" <support@example.com>`,
+		"List-Unsubscribe: <mailto:unsubscribe@example.com>",
+		"X-Campaign: tasks",
+		"Content-Type: multipart/mixed",
+		"Content-Type: multipart/related",
+		"Content-Type: multipart/alternative",
+		"Content-Id: <logo.png>",
+		"Content-Disposition: inline; filename=logo.png",
+		"Content-Disposition: attachment; filename=tasks.csv",
+	} {
+		if !strings.Contains(eml, want) {
+			t.Errorf("eml doesn't contain %q:\n%s", want, eml)
+		}
+	}
+}
+
+func TestMail_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		mail    Mail
+		wantErr bool
+	}{
+		{name: "custom header", mail: Mail{Headers: map[string]string{"X-Priority": "1"}}},
+		{name: "reserved header", mail: Mail{Headers: map[string]string{"reply-to": "someone@example.com"}}, wantErr: true},
+		{name: "attachment without filename", mail: Mail{Attachments: []Attachment{{Data: []byte("x")}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.mail.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Mail.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
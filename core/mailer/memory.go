@@ -0,0 +1,95 @@
+package mailer
+
+import (
+	"strings"
+	"sync"
+)
+
+// MemoryMailer keeps the sent mails in memory, it's meant to be used in tests to assert what was sent
+type MemoryMailer struct {
+	mu    sync.RWMutex
+	mails []Mail
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+func (mailer *MemoryMailer) Send(email Mail) error {
+	if err := email.Validate(); err != nil {
+		return err
+	}
+
+	mailer.mu.Lock()
+	defer mailer.mu.Unlock()
+
+	mailer.mails = append(mailer.mails, email)
+
+	return nil
+}
+
+// Messages returns a copy of every mail sent, in order
+func (mailer *MemoryMailer) Messages() []Mail {
+	mailer.mu.RLock()
+	defer mailer.mu.RUnlock()
+
+	mails := make([]Mail, len(mailer.mails))
+	copy(mails, mailer.mails)
+
+	return mails
+}
+
+// Last returns the last mail sent
+func (mailer *MemoryMailer) Last() (Mail, bool) {
+	mailer.mu.RLock()
+	defer mailer.mu.RUnlock()
+
+	if len(mailer.mails) == 0 {
+		return Mail{}, false
+	}
+
+	return mailer.mails[len(mailer.mails)-1], true
+}
+
+// To returns the mails where the given address is a recipient (To, Cc or Bcc)
+func (mailer *MemoryMailer) To(address string) []Mail {
+	mailer.mu.RLock()
+	defer mailer.mu.RUnlock()
+
+	var mails []Mail
+	for _, mail := range mailer.mails {
+		if hasRecipient(mail, address) {
+			mails = append(mails, mail)
+		}
+	}
+
+	return mails
+}
+
+// Len returns how many mails were sent
+func (mailer *MemoryMailer) Len() int {
+	mailer.mu.RLock()
+	defer mailer.mu.RUnlock()
+
+	return len(mailer.mails)
+}
+
+// Reset forgets every mail sent
+func (mailer *MemoryMailer) Reset() {
+	mailer.mu.Lock()
+	defer mailer.mu.Unlock()
+
+	mailer.mails = nil
+}
+
+func hasRecipient(mail Mail, address string) bool {
+	for _, recipients := range [][]EmailInfo{mail.To, mail.Cc, mail.Bcc} {
+		for _, recipient := range recipients {
+			if strings.EqualFold(recipient.Email, address) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
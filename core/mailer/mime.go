@@ -0,0 +1,224 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+)
+
+func (e EmailInfo) String() string {
+	address := mail.Address{Name: e.Name, Address: e.Email}
+	return address.String()
+}
+
+func joinAddresses(infos []EmailInfo) string {
+	addresses := make([]string, 0, len(infos))
+	for _, info := range infos {
+		addresses = append(addresses, info.String())
+	}
+
+	return strings.Join(addresses, ", ")
+}
+
+func newMessageID(from string) string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	domain := "localhost"
+	if _, after, found := strings.Cut(from, "@"); found {
+		domain = after
+	}
+
+	return fmt.Sprintf("<%x@%s>", b, domain)
+}
+
+// mimePart is an encoded entity, it can be the whole body or a part of a multipart
+type mimePart struct {
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+func textPart(contentType, body string) (mimePart, error) {
+	var buf bytes.Buffer
+
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return mimePart{}, err
+	}
+
+	if err := qp.Close(); err != nil {
+		return mimePart{}, err
+	}
+
+	return mimePart{
+		header: textproto.MIMEHeader{
+			"Content-Type":              {contentType + "; charset=utf-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		},
+		body: buf.Bytes(),
+	}, nil
+}
+
+func attachmentPart(attachment Attachment) mimePart {
+	header := textproto.MIMEHeader{
+		"Content-Type":              {mime.FormatMediaType(attachment.contentType(), map[string]string{"name": attachment.Filename})},
+		"Content-Transfer-Encoding": {"base64"},
+	}
+
+	if attachment.Inline() {
+		header.Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": attachment.Filename}))
+		header.Set("Content-ID", "<"+attachment.ContentID+">")
+	} else {
+		header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	}
+
+	// RFC 2045 limits the encoded lines to 76 characters
+	encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+
+	var buf bytes.Buffer
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded)
+
+	return mimePart{header: header, body: buf.Bytes()}
+}
+
+func multipartOf(subtype string, parts ...mimePart) (mimePart, error) {
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	for _, part := range parts {
+		w, err := writer.CreatePart(part.header)
+		if err != nil {
+			return mimePart{}, err
+		}
+
+		if _, err := w.Write(part.body); err != nil {
+			return mimePart{}, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return mimePart{}, err
+	}
+
+	return mimePart{
+		header: textproto.MIMEHeader{
+			"Content-Type": {"multipart/" + subtype + "; boundary=" + writer.Boundary()},
+		},
+		body: buf.Bytes(),
+	}, nil
+}
+
+// body builds mixed(related(alternative(text, html), inline...), attachments...) skipping the levels that aren't needed
+func (m Mail) body() (mimePart, error) {
+	var alternatives []mimePart
+
+	if m.PlainText != "" || m.HTML == "" {
+		part, err := textPart("text/plain", m.PlainText)
+		if err != nil {
+			return mimePart{}, err
+		}
+		alternatives = append(alternatives, part)
+	}
+
+	if m.HTML != "" {
+		part, err := textPart("text/html", m.HTML)
+		if err != nil {
+			return mimePart{}, err
+		}
+		alternatives = append(alternatives, part)
+	}
+
+	body, err := multipartOf("alternative", alternatives...)
+	if err != nil {
+		return mimePart{}, err
+	}
+
+	related := []mimePart{body}
+	mixed := []mimePart{}
+	for _, attachment := range m.Attachments {
+		if attachment.Inline() {
+			related = append(related, attachmentPart(attachment))
+		} else {
+			mixed = append(mixed, attachmentPart(attachment))
+		}
+	}
+
+	body, err = multipartOf("related", related...)
+	if err != nil {
+		return mimePart{}, err
+	}
+
+	return multipartOf("mixed", append([]mimePart{body}, mixed...)...)
+}
+
+// MIME encodes the mail as a RFC 5322 message, the same content a SMTP server would receive.
+//
+// Bcc is kept in the headers since it's meant to inspect the mails, not to deliver them.
+func (m Mail) MIME(date time.Time) ([]byte, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	body, err := m.body()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	header := func(key, value string) {
+		if value != "" {
+			fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+		}
+	}
+
+	header("From", m.From.String())
+	if m.ReplyTo != nil {
+		header("Reply-To", m.ReplyTo.String())
+	}
+	header("To", joinAddresses(m.To))
+	header("Cc", joinAddresses(m.Cc))
+	header("Bcc", joinAddresses(m.Bcc))
+	header("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header("Date", date.Format(time.RFC1123Z))
+	header("Message-ID", newMessageID(m.From.Email))
+	header("List-Unsubscribe", m.ListUnsubscribe)
+
+	keys := make([]string, 0, len(m.Headers))
+	for key := range m.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		header(textproto.CanonicalMIMEHeaderKey(key), mime.QEncoding.Encode("utf-8", m.Headers[key]))
+	}
+
+	header("MIME-Version", "1.0")
+	header("Content-Type", body.header.Get("Content-Type"))
+	header("Content-Transfer-Encoding", body.header.Get("Content-Transfer-Encoding"))
+	header("Content-Disposition", body.header.Get("Content-Disposition"))
+	header("Content-ID", body.header.Get("Content-ID"))
+
+	buf.WriteString("\r\n")
+	buf.Write(body.body)
+
+	return buf.Bytes(), nil
+}
@@ -13,8 +13,19 @@ func NewNoopMailer(config env.Config) (Mailer, error) {
 }
 
 func (mailer *noopMailer) Send(email Mail) error {
+	if err := email.Validate(); err != nil {
+		return err
+	}
+
+	// Attachments are printed as a summary, otherwise the whole file would go to stdout
+	attachments := email.Attachments
+	email.Attachments = nil
 
 	fmt.Printf("%+v\n", email)
 
+	for _, attachment := range attachments {
+		fmt.Printf("  attachment: %s (%s, %d bytes, inline=%t)\n", attachment.Filename, attachment.contentType(), len(attachment.Data), attachment.Inline())
+	}
+
 	return nil
 }
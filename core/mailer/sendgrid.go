@@ -1,6 +1,7 @@
 package mailer
 
 import (
+	"encoding/base64"
 	"fmt"
 
 	"github.com/sendgrid/sendgrid-go"
@@ -21,12 +22,42 @@ func NewSendgridMailer(config env.Config) (Mailer, error) {
 }
 
 func (mailer *sendgridMailer) Send(e Mail) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+
 	// create new *SGMailV3
 	m := mail.NewV3Mail()
 
 	from := mail.NewEmail(e.From.Name, e.From.Email)
 	m.SetFrom(from)
 
+	if e.ReplyTo != nil {
+		m.SetReplyTo(mail.NewEmail(e.ReplyTo.Name, e.ReplyTo.Email))
+	}
+
+	if e.ListUnsubscribe != "" {
+		m.SetHeader("List-Unsubscribe", e.ListUnsubscribe)
+	}
+
+	for key, value := range e.Headers {
+		m.SetHeader(key, value)
+	}
+
+	for _, attachment := range e.Attachments {
+		a := mail.NewAttachment().
+			SetContent(base64.StdEncoding.EncodeToString(attachment.Data)).
+			SetType(attachment.contentType()).
+			SetFilename(attachment.Filename).
+			SetDisposition("attachment")
+
+		if attachment.Inline() {
+			a.SetDisposition("inline").SetContentID(attachment.ContentID)
+		}
+
+		m.AddAttachment(a)
+	}
+
 	if e.PlainText != "" {
 		content := mail.NewContent("text/plain", e.PlainText)
 		m.AddContent(content)
@@ -1,7 +1,9 @@
 package mailer
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,7 +15,9 @@ type simpleMailMailer struct {
 	server *simplemail.SMTPServer
 	client *simplemail.SMTPClient
 
-	mu *sync.Mutex
+	mu     *sync.Mutex
+	ticker *time.Ticker
+	done   chan struct{}
 }
 
 func NewSimpleMailMailer(config env.Config) (Mailer, error) {
@@ -29,35 +33,72 @@ func NewSimpleMailMailer(config env.Config) (Mailer, error) {
 	server.KeepAlive = true
 	server.Username = config.SMTPClientID
 	server.Password = config.SMTPClientSecret
-	server.Encryption = simplemail.EncryptionSTARTTLS
+
+	switch strings.ToLower(config.SMTPEncryption) {
+	case "", "starttls":
+		server.Encryption = simplemail.EncryptionSTARTTLS
+	case "ssl", "tls":
+		server.Encryption = simplemail.EncryptionSSLTLS
+	case "none":
+		// Local capture servers (e.g: mailpit) usually don't support TLS nor auth
+		server.Encryption = simplemail.EncryptionNone
+		server.Authentication = simplemail.AuthNone
+	default:
+		return nil, fmt.Errorf("unknown smtp encryption %q", config.SMTPEncryption)
+	}
 	smtpClient, err := server.Connect()
 	if err != nil {
 		return nil, err
 	}
 	smtpClient.KeepAlive = true
 
-	mu := sync.Mutex{}
+	mailer := &simpleMailMailer{
+		server: server,
+		client: smtpClient,
+		mu:     &sync.Mutex{},
+		ticker: time.NewTicker(10 * time.Second),
+		done:   make(chan struct{}),
+	}
 
 	// https://github.com/xhit/go-simple-mail/issues/23#issuecomment-752015639
-	ticker := time.NewTicker(10 * time.Second)
-	go func() {
-		for range ticker.C {
-			mu.Lock()
-			if smtpClient.Noop() != nil {
-				smtpClient, _ = server.Connect()
+	go mailer.keepAlive()
+
+	return mailer, nil
+}
+
+// keepAlive reconnects when the server closed the idle connection, until Close
+func (mailer *simpleMailMailer) keepAlive() {
+	for {
+		select {
+		case <-mailer.ticker.C:
+			mailer.mu.Lock()
+			if mailer.client.Noop() != nil {
+				if smtpClient, err := mailer.server.Connect(); err == nil {
+					mailer.client = smtpClient
+				}
 			}
-			mu.Unlock()
+			mailer.mu.Unlock()
+		case <-mailer.done:
+			return
 		}
-	}()
+	}
+}
 
-	return &simpleMailMailer{
-		server: server,
-		client: smtpClient,
-		mu:     &mu,
-	}, nil
+// Close stops the keep alive and quits the connection
+func (mailer *simpleMailMailer) Close() error {
+	mailer.ticker.Stop()
+	close(mailer.done)
+
+	mailer.mu.Lock()
+	defer mailer.mu.Unlock()
+
+	return mailer.client.Quit()
 }
 
 func (mailer *simpleMailMailer) Send(email Mail) error {
+	if err := email.Validate(); err != nil {
+		return err
+	}
 
 	var tos, cc, bcc []string
 	for _, v := range email.To {
@@ -77,12 +118,43 @@ func (mailer *simpleMailMailer) Send(email Mail) error {
 		AddBcc(bcc...).
 		SetSubject(email.Subject)
 
-	if email.PlainText != "" {
-		mail.SetBody(simplemail.TextPlain, email.PlainText)
+	if email.ReplyTo != nil {
+		mail.SetReplyTo(email.ReplyTo.Email)
+	}
+
+	if email.ListUnsubscribe != "" {
+		mail.SetListUnsubscribe(email.ListUnsubscribe)
+	}
+
+	for key, value := range email.Headers {
+		mail.AddHeader(key, value)
 	}
 
-	if email.HTML != "" {
+	// HTML goes as an alternative part, otherwise SetBody would replace the plain text
+	switch {
+	case email.PlainText != "" && email.HTML != "":
+		mail.SetBody(simplemail.TextPlain, email.PlainText)
+		mail.AddAlternative(simplemail.TextHTML, email.HTML)
+	case email.HTML != "":
 		mail.SetBody(simplemail.TextHTML, email.HTML)
+	default:
+		mail.SetBody(simplemail.TextPlain, email.PlainText)
+	}
+
+	for _, attachment := range email.Attachments {
+		file := &simplemail.File{
+			Name:     attachment.Filename,
+			MimeType: attachment.contentType(),
+			Data:     attachment.Data,
+		}
+
+		// go-simple-mail matches the HTML cid:<name> references by the file name
+		if attachment.Inline() {
+			file.Name = attachment.ContentID
+			file.Inline = true
+		}
+
+		mail.Attach(file)
 	}
 
 	if mail.Error != nil {
@@ -0,0 +1,371 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/mirusky-dev/challenge-18/core/env"
+	"github.com/mirusky-dev/challenge-18/core/i18n"
+)
+
+//go:embed templates
+var embeddedTemplates embed.FS
+
+// DefaultLocale is used when the user has no locale or the requested locale has no template
+const DefaultLocale = i18n.DefaultLocale
+
+// logoFile is attached inline to every HTML mail when present, the layout references it as cid:logo.png
+const logoFile = "logo.png"
+
+const (
+	TemplateWelcome           = "welcome"
+	TemplateResetPassword     = "reset-password"
+	TemplateEmailVerification = "email-verification"
+	TemplateTaskCompleted     = "task-completed"
+	TemplateDigest            = "digest"
+)
+
+var ErrTemplateNotFound = errors.New("mail template not found")
+
+// Content is the result of a rendered template, ready to be placed in a Mail
+type Content struct {
+	Subject     string
+	PlainText   string
+	HTML        string
+	Attachments []Attachment
+}
+
+// Brand holds the values shared by every message through the layout
+type Brand struct {
+	Name   string
+	Sender string
+	URL    string
+
+	// Logo is the src of the logo image, empty when there is no logo
+	Logo htmltemplate.URL
+}
+
+type WelcomeData struct {
+	Username string
+}
+
+type ResetPasswordData struct {
+	Username string
+	Link     string
+}
+
+type EmailVerificationData struct {
+	Username string
+	Link     string
+}
+
+type TaskCompletedData struct {
+	ManagerName string
+	TechName    string
+	TechID      string
+	TaskID      string
+	TaskSummary string
+	PerformedAt string
+}
+
+// DigestTask is a line of the digest tables, Date is the one that matters for the section
+type DigestTask struct {
+	ID       string
+	Summary  string
+	TechName string
+	Date     string
+}
+
+type DigestData struct {
+	ManagerName string
+	Frequency   string // daily or weekly
+	From        string
+	To          string
+
+	Completed []DigestTask
+	Created   []DigestTask
+	Overdue   []DigestTask
+}
+
+// samples are used to preview templates without touching real data
+var samples = map[string]any{
+	TemplateWelcome: WelcomeData{
+		Username: "jane.doe",
+	},
+	TemplateResetPassword: ResetPasswordData{
+		Username: "jane.doe",
+		Link:     "http://localhost:4000/app/reset-password/00000000-0000-0000-0000-000000000000",
+	},
+	TemplateEmailVerification: EmailVerificationData{
+		Username: "jane.doe",
+		Link:     "http://localhost:4000/app/verify-email/00000000-0000-0000-0000-000000000000",
+	},
+	TemplateTaskCompleted: TaskCompletedData{
+		ManagerName: "manager",
+		TechName:    "jane.doe",
+		TechID:      "7aff9b64-d52a-485c-a738-fcae9e5cede0",
+		TaskID:      "30a97ed5-cee3-46d2-aebb-bf3f57417223",
+		TaskSummary: "Replace the air conditioner filter",
+		PerformedAt: "2024-07-20T10:00:00Z",
+	},
+	TemplateDigest: DigestData{
+		ManagerName: "manager",
+		Frequency:   "daily",
+		From:        "2024-07-19T08:00:00Z",
+		To:          "2024-07-20T08:00:00Z",
+		Completed: []DigestTask{
+			{ID: "30a97ed5-cee3-46d2-aebb-bf3f57417223", Summary: "Replace the air conditioner filter", TechName: "jane.doe", Date: "2024-07-19T10:00:00Z"},
+		},
+		Created: []DigestTask{
+			{ID: "2f436752-0e23-4eb1-9963-ee8e9d04e972", Summary: "Check the generator", TechName: "john.doe", Date: "2024-07-19T14:30:00Z"},
+		},
+	},
+}
+
+type TemplateRenderer interface {
+	// Render executes the named template with the given data using the closest available locale.
+	Render(name, locale string, data any) (Content, error)
+
+	// Preview renders the named template with sample data.
+	Preview(name, locale string) (Content, error)
+
+	// Templates returns the name of every known template.
+	Templates() []string
+
+	// Locales returns the locales that have at least one template.
+	Locales() []string
+}
+
+type templateRenderer struct {
+	brand Brand
+
+	// sources are looked up in order, the first one containing the file wins
+	sources []fs.FS
+}
+
+// templateView is the value given to the templates, the message specific data is under Data
+type templateView struct {
+	Brand  Brand
+	Locale string
+	Data   any
+}
+
+// NewTemplateRenderer creates a renderer using the embedded templates.
+//
+// When config.MailTemplatesDir is set its files take precedence over the embedded ones,
+// following the same <locale>/<name>.<html|txt> layout.
+func NewTemplateRenderer(config env.Config) (TemplateRenderer, error) {
+	embedded, err := fs.Sub(embeddedTemplates, "templates")
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []fs.FS
+	if config.MailTemplatesDir != "" {
+		if _, err := os.Stat(config.MailTemplatesDir); err != nil {
+			return nil, err
+		}
+		sources = append(sources, os.DirFS(config.MailTemplatesDir))
+	}
+	sources = append(sources, embedded)
+
+	return &templateRenderer{
+		brand: Brand{
+			Name:   config.MailBrandName,
+			Sender: config.EmailSenderName,
+			URL:    config.MailBrandURL,
+		},
+		sources: sources,
+	}, nil
+}
+
+func (r *templateRenderer) Render(name, locale string, data any) (Content, error) {
+	locale = r.resolveLocale(name, locale)
+	if locale == "" {
+		return Content{}, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+
+	view := templateView{
+		Brand:  r.brand,
+		Locale: locale,
+		Data:   data,
+	}
+
+	var attachments []Attachment
+	if logo, err := r.readFile(logoFile); err == nil {
+		view.Brand.Logo = htmltemplate.URL("cid:" + logoFile)
+		attachments = append(attachments, Attachment{
+			Filename:    logoFile,
+			ContentType: "image/png",
+			Data:        logo,
+			ContentID:   logoFile,
+		})
+	}
+
+	subject, text, err := r.renderText(name, locale, view)
+	if err != nil {
+		return Content{}, err
+	}
+
+	html, err := r.renderHTML(name, locale, view)
+	if err != nil {
+		return Content{}, err
+	}
+
+	// The logo is only useful when there is an HTML body to show it
+	if html == "" {
+		attachments = nil
+	}
+
+	return Content{
+		Subject:     subject,
+		PlainText:   text,
+		HTML:        html,
+		Attachments: attachments,
+	}, nil
+}
+
+func (r *templateRenderer) Preview(name, locale string) (Content, error) {
+	data, ok := samples[name]
+	if !ok {
+		return Content{}, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+
+	return r.Render(name, locale, data)
+}
+
+func (r *templateRenderer) Templates() []string {
+	return []string{
+		TemplateWelcome,
+		TemplateResetPassword,
+		TemplateEmailVerification,
+		TemplateTaskCompleted,
+		TemplateDigest,
+	}
+}
+
+func (r *templateRenderer) Locales() []string {
+	seen := map[string]bool{}
+	var locales []string
+
+	for _, source := range r.sources {
+		entries, err := fs.ReadDir(source, ".")
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() && !seen[entry.Name()] {
+				seen[entry.Name()] = true
+				locales = append(locales, entry.Name())
+			}
+		}
+	}
+
+	return locales
+}
+
+// resolveLocale finds the best locale for the template, e.g: pt-BR -> pt -> en
+func (r *templateRenderer) resolveLocale(name, locale string) string {
+	for _, candidate := range i18n.Candidates(locale) {
+		if _, err := r.readFile(candidate + "/" + name + ".txt"); err == nil {
+			return candidate
+		}
+	}
+
+	return ""
+}
+
+func (r *templateRenderer) readFile(name string) ([]byte, error) {
+	for _, source := range r.sources {
+		b, err := fs.ReadFile(source, name)
+		if err == nil {
+			return b, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+}
+
+// renderText renders the plain text body and the subject, both defined in <locale>/<name>.txt
+func (r *templateRenderer) renderText(name, locale string, view templateView) (string, string, error) {
+	layout, err := r.readFile("layout.txt")
+	if err != nil {
+		return "", "", err
+	}
+
+	message, err := r.readFile(locale + "/" + name + ".txt")
+	if err != nil {
+		return "", "", err
+	}
+
+	tmpl, err := texttemplate.New("layout").Funcs(funcs(locale)).Parse(string(layout))
+	if err != nil {
+		return "", "", err
+	}
+
+	if _, err := tmpl.Parse(string(message)); err != nil {
+		return "", "", err
+	}
+
+	var subject, body bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&subject, "subject", view); err != nil {
+		return "", "", err
+	}
+
+	if err := tmpl.ExecuteTemplate(&body, "layout", view); err != nil {
+		return "", "", err
+	}
+
+	return strings.TrimSpace(subject.String()), strings.TrimSpace(body.String()) + "\n", nil
+}
+
+// renderHTML renders <locale>/<name>.html inside the branded layout, a missing html template is not an error
+func (r *templateRenderer) renderHTML(name, locale string, view templateView) (string, error) {
+	message, err := r.readFile(locale + "/" + name + ".html")
+	if err != nil {
+		return "", nil
+	}
+
+	layout, err := r.readFile("layout.html")
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err := htmltemplate.New("layout").Funcs(funcs(locale)).Parse(string(layout))
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := tmpl.Parse(string(message)); err != nil {
+		return "", err
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&body, "layout", view); err != nil {
+		return "", err
+	}
+
+	return body.String(), nil
+}
+
+// funcs are available to every template, t translates a key of the i18n catalogs, e.g: {{t "mail.footer" "brand" .Brand.Name}}
+func funcs(locale string) map[string]any {
+	return map[string]any{
+		"t": func(key string, pairs ...any) string {
+			params := map[string]any{}
+			for i := 0; i+1 < len(pairs); i += 2 {
+				params[fmt.Sprint(pairs[i])] = pairs[i+1]
+			}
+
+			return i18n.T(locale, key, params)
+		},
+	}
+}
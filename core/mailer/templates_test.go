@@ -0,0 +1,124 @@
+package mailer
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/mirusky-dev/challenge-18/core/env"
+)
+
+func Test_templateRenderer_Render(t *testing.T) {
+	renderer, err := NewTemplateRenderer(env.Config{MailBrandName: "Rocket", EmailSenderName: "Rocket (No Reply)"})
+	if err != nil {
+		t.Fatal("precondition failed: NewTemplateRenderer() error = ", err)
+	}
+
+	tests := []struct {
+		name        string
+		template    string
+		locale      string
+		wantSubject string
+		wantText    string
+		wantHTML    string
+		wantErr     bool
+	}{
+		{
+			name:        "default locale",
+			template:    TemplateWelcome,
+			locale:      "",
+			wantSubject: "Welcome to Rocket",
+			wantText:    "Dear jane.doe",
+			wantHTML:    "<p>Dear jane.doe,</p>",
+		},
+		{
+			name:        "user locale",
+			template:    TemplateWelcome,
+			locale:      "pt-BR",
+			wantSubject: "Bem-vindo ao Rocket",
+			wantText:    "Olá jane.doe",
+			wantHTML:    `<html lang="pt-BR">`,
+		},
+		{
+			name:        "spanish",
+			template:    TemplateTaskCompleted,
+			locale:      "es-AR",
+			wantSubject: "Tarea completada",
+			wantText:    "Recibes este email porque tienes una cuenta en Rocket.",
+			wantHTML:    `<html lang="es">`,
+		},
+		{
+			name:        "unknown locale falls back to default",
+			template:    TemplateResetPassword,
+			locale:      "de-DE",
+			wantSubject: "Password Recovery",
+			wantText:    "/app/reset-password/",
+			wantHTML:    `<html lang="en">`,
+		},
+		{
+			name:        "digest",
+			template:    TemplateDigest,
+			locale:      "pt-BR",
+			wantSubject: "Seu resumo diário",
+			wantText:    "- Replace the air conditioner filter por jane.doe em 2024-07-19T10:00:00Z",
+			wantHTML:    "<td>Check the generator</td><td>john.doe</td>",
+		},
+		{
+			name:     "unknown template",
+			template: "unknown",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderer.Preview(tt.template, tt.locale)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("templateRenderer.Preview() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Subject != tt.wantSubject {
+				t.Errorf("templateRenderer.Preview() subject = %q, want %q", got.Subject, tt.wantSubject)
+			}
+			if !strings.Contains(got.PlainText, tt.wantText) {
+				t.Errorf("templateRenderer.Preview() text = %q, want it to contain %q", got.PlainText, tt.wantText)
+			}
+			if !strings.Contains(got.HTML, tt.wantHTML) {
+				t.Errorf("templateRenderer.Preview() html = %q, want it to contain %q", got.HTML, tt.wantHTML)
+			}
+		})
+	}
+}
+
+func Test_templateRenderer_Override(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(path.Join(dir, "en"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	override := `{{define "subject"}}Custom welcome{{end}}{{define "content"}}Hi {{.Data.Username}}{{end}}`
+	if err := os.WriteFile(path.Join(dir, "en", TemplateWelcome+".txt"), []byte(override), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	renderer, err := NewTemplateRenderer(env.Config{MailTemplatesDir: dir})
+	if err != nil {
+		t.Fatal("precondition failed: NewTemplateRenderer() error = ", err)
+	}
+
+	got, err := renderer.Preview(TemplateWelcome, DefaultLocale)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Subject != "Custom welcome" || !strings.HasPrefix(got.PlainText, "Hi jane.doe") {
+		t.Errorf("templateRenderer.Preview() = %+v, want the overridden template", got)
+	}
+
+	// html wasn't overridden, so the embedded one is used
+	if !strings.Contains(got.HTML, "<p>Dear jane.doe,</p>") {
+		t.Errorf("templateRenderer.Preview() html = %q, want the embedded template", got.HTML)
+	}
+}
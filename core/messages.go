@@ -0,0 +1,39 @@
+package core
+
+// Keys of the messages given with WithMessageKey, each one has a translation in the i18n catalogs as exception.<key>
+const (
+	MessageMissingToken            = "missing-token"
+	MessageInvalidToken            = "invalid-token"
+	MessageTokenRevoked            = "token-revoked"
+	MessageMissingRefreshToken     = "missing-refresh-token"
+	MessageInvalidRefreshToken     = "invalid-refresh-token"
+	MessageWrongCredentials        = "wrong-credentials"
+	MessageUsernameOrEmailTaken    = "username-or-email-taken"
+	MessageResetCodeExpired        = "reset-code-expired"
+	MessageVerificationCodeExpired = "verification-code-expired"
+	MessageUnsupportedLocale       = "unsupported-locale"
+	MessageManagersCantCreateTasks = "managers-cant-create-tasks"
+	MessageManagersCantUpdateTasks = "managers-cant-update-tasks"
+	MessageTechsCantDeleteTasks    = "techs-cant-delete-tasks"
+	MessageMissingRole             = "missing-role"       // {{.roles}}
+	MessageMissingPermission       = "missing-permission" // {{.permissions}}
+)
+
+// MessageKeys lists the keys of the messages
+var MessageKeys = []string{
+	MessageMissingToken,
+	MessageInvalidToken,
+	MessageTokenRevoked,
+	MessageMissingRefreshToken,
+	MessageInvalidRefreshToken,
+	MessageWrongCredentials,
+	MessageUsernameOrEmailTaken,
+	MessageResetCodeExpired,
+	MessageVerificationCodeExpired,
+	MessageUnsupportedLocale,
+	MessageManagersCantCreateTasks,
+	MessageManagersCantUpdateTasks,
+	MessageTechsCantDeleteTasks,
+	MessageMissingRole,
+	MessageMissingPermission,
+}
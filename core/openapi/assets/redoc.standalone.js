@@ -0,0 +1,4 @@
+// Placeholder for the Redoc v2.1.3 standalone bundle, replace it with: go generate ./core/openapi
+document.addEventListener("DOMContentLoaded", function () {
+	document.body.textContent = "The Redoc bundle isn't embedded, run go generate ./core/openapi and rebuild. The document is at /api/openapi.json.";
+});
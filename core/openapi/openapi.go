@@ -0,0 +1,249 @@
+// Package openapi builds an OpenAPI 3.1 document from the routes and the Go types they exchange.
+//
+// The request DTOs are described by probing their ozzo validation rules, see Validator.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/mirusky-dev/challenge-18/core"
+)
+
+const (
+	// BearerAuth is the security scheme required by the non-public routes
+	BearerAuth = "bearerAuth"
+
+	errorResponse = "#/components/responses/Error"
+)
+
+// Route describes an endpoint
+type Route struct {
+	Method      string
+	Path        string // fiber style, e.g: /api/v1/tasks/:id
+	OperationID string
+	Summary     string
+	Description string
+	Tags        []string
+
+	// Public routes don't need a token, the others accept any of the Roles (or any user when empty)
+	Public bool
+	Roles  []string
+
+	Query    any // struct with `query` tags
+	Request  any // JSON body
+	Response any // JSON body, nil when there's none
+
+	Status      int    // default 200
+	ContentType string // default application/json
+}
+
+type Builder struct {
+	doc       Document
+	generator *generator
+	tags      map[string]bool
+}
+
+func NewBuilder(info Info) *Builder {
+	b := &Builder{
+		doc: Document{
+			OpenAPI: Version,
+			Info:    info,
+			Paths:   map[string]*PathItem{},
+		},
+		generator: newGenerator(),
+		tags:      map[string]bool{},
+	}
+
+	b.doc.Components.Responses = map[string]*Response{
+		"Error": {
+			Description: "Error, RFC 7807 when the request accepts application/problem+json",
+			Content: map[string]MediaType{
+				"application/json":              {Schema: b.generator.schema(reflect.TypeOf(core.Exception{}))},
+				core.MIMEApplicationProblemJSON: {Schema: b.generator.schema(reflect.TypeOf(core.Problem{}))},
+			},
+		},
+	}
+
+	b.doc.Components.SecuritySchemes = map[string]*SecurityScheme{
+		BearerAuth: {
+			Type:         "http",
+			Scheme:       "bearer",
+			BearerFormat: "JWT",
+			Description:  "Access token from /api/v1/auth/login, the stream also accepts it in the access_token query parameter.",
+		},
+	}
+
+	return b
+}
+
+// Server adds a server URL
+func (b *Builder) Server(url, description string) *Builder {
+	b.doc.Servers = append(b.doc.Servers, Server{URL: url, Description: description})
+	return b
+}
+
+// Enum registers a set of values, a field restricted by v.In is documented with the largest set it accepts
+func (b *Builder) Enum(values ...string) *Builder {
+	b.generator.enums = append(b.generator.enums, values)
+	return b
+}
+
+func (b *Builder) Add(routes ...Route) *Builder {
+	for _, route := range routes {
+		b.add(route)
+	}
+
+	return b
+}
+
+func (b *Builder) add(route Route) {
+	op := &Operation{
+		OperationID: route.OperationID,
+		Summary:     route.Summary,
+		Description: route.Description,
+		Tags:        route.Tags,
+		Responses:   map[string]*Response{},
+	}
+
+	for _, tag := range route.Tags {
+		if !b.tags[tag] {
+			b.tags[tag] = true
+			b.doc.Tags = append(b.doc.Tags, Tag{Name: tag})
+		}
+	}
+
+	// /api/v1/tasks/:id becomes /api/v1/tasks/{id}
+	segments := strings.Split(route.Path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			name := strings.TrimPrefix(segment, ":")
+			segments[i] = "{" + name + "}"
+
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:     name,
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			})
+		}
+	}
+	path := strings.Join(segments, "/")
+	hasPathParams := len(op.Parameters) > 0
+
+	if route.Query != nil {
+		op.Parameters = append(op.Parameters, b.queryParameters(reflect.TypeOf(route.Query))...)
+	}
+
+	if route.Request != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]MediaType{
+				"application/json": {Schema: b.generator.schema(reflect.TypeOf(route.Request))},
+			},
+		}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	contentType := route.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	response := &Response{Description: http.StatusText(status)}
+	if route.Response != nil {
+		response.Content = map[string]MediaType{
+			contentType: {Schema: b.generator.schema(reflect.TypeOf(route.Response))},
+		}
+	} else if route.ContentType != "" {
+		response.Content = map[string]MediaType{
+			contentType: {Schema: &Schema{Type: "string"}},
+		}
+	}
+	op.Responses[strconv.Itoa(status)] = response
+
+	// Errors share the core.Exception shape
+	if route.Request != nil || route.Query != nil || hasPathParams {
+		op.Responses["400"] = &Response{Ref: errorResponse}
+	}
+
+	if !route.Public {
+		op.Security = []map[string][]string{{BearerAuth: {}}}
+		op.Responses["401"] = &Response{Ref: errorResponse}
+
+		if len(route.Roles) > 0 {
+			op.Responses["403"] = &Response{Ref: errorResponse}
+
+			roles := fmt.Sprintf("Requires one of the roles: %s.", strings.Join(route.Roles, ", "))
+			op.Description = strings.TrimSpace(op.Description + "\n\n" + roles)
+		}
+	}
+
+	if hasPathParams {
+		op.Responses["404"] = &Response{Ref: errorResponse}
+	}
+
+	op.Responses["default"] = &Response{Ref: errorResponse}
+
+	item, ok := b.doc.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		b.doc.Paths[path] = item
+	}
+
+	switch strings.ToUpper(route.Method) {
+	case http.MethodGet:
+		item.Get = op
+	case http.MethodPut:
+		item.Put = op
+	case http.MethodPost:
+		item.Post = op
+	case http.MethodDelete:
+		item.Delete = op
+	case http.MethodPatch:
+		item.Patch = op
+	}
+}
+
+func (b *Builder) queryParameters(t reflect.Type) []Parameter {
+	var params []Parameter
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			params = append(params, b.queryParameters(f.Type)...)
+			continue
+		}
+
+		name := f.Tag.Get("query")
+		if name == "" || name == "-" || !f.IsExported() {
+			continue
+		}
+
+		typ := f.Type
+		if typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+
+		params = append(params, Parameter{
+			Name:   name,
+			In:     "query",
+			Schema: b.generator.schema(typ),
+		})
+	}
+
+	return params
+}
+
+func (b *Builder) Document() Document {
+	b.doc.Components.Schemas = b.generator.components
+	return b.doc
+}
@@ -0,0 +1,114 @@
+package openapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	v "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirusky-dev/challenge-18/core"
+)
+
+type createThing struct {
+	Name   string   `json:"name"`
+	Email  string   `json:"email"`
+	Kind   string   `json:"kind"`
+	Tags   []string `json:"tags"`
+	Secret *string  `json:"secret"`
+	Hidden string   `json:"-"`
+}
+
+func (s createThing) Validate(ctx context.Context) error {
+	return v.ValidateStruct(&s,
+		v.Field(&s.Name, v.Required),
+		v.Field(&s.Email, v.Required, is.Email),
+		v.Field(&s.Kind, v.In("small", "big")),
+		v.Field(&s.Tags, v.Each(v.In("a", "b", "c"))),
+		v.Field(&s.Secret, v.NilOrNotEmpty, v.Length(16, 64)),
+	)
+}
+
+// Either one is required, so neither is documented as required
+type updateThing struct {
+	Name *string `json:"name"`
+	Done *bool   `json:"done"`
+}
+
+func (s updateThing) Validate(ctx context.Context) error {
+	return v.ValidateStruct(&s,
+		v.Field(&s.Name, v.Required.When(s.Done == nil)),
+		v.Field(&s.Done, v.Required.When(s.Name == nil)),
+	)
+}
+
+type thing struct {
+	ID      string  `json:"id"`
+	Note    *string `json:"note"`
+	Deleted bool    `json:"deleted,omitempty"`
+}
+
+func TestBuilder(t *testing.T) {
+	doc := NewBuilder(Info{Title: "Test", Version: "1"}).
+		Enum("small", "big", "huge").
+		Enum("small", "big").
+		Enum("a", "b", "c").
+		Add(
+			Route{Method: http.MethodPost, Path: "/things", Request: createThing{}, Response: thing{}, Status: http.StatusCreated, Roles: []string{"admin"}},
+			Route{Method: http.MethodPut, Path: "/things/:id", Request: updateThing{}, Response: thing{}},
+			Route{Method: http.MethodGet, Path: "/things", Public: true, Query: core.PaginationParams{}, Response: core.PagedResponse[thing]{}},
+		).
+		Document()
+
+	assert.Equal(t, Version, doc.OpenAPI)
+	require.Contains(t, doc.Paths, "/things")
+	require.Contains(t, doc.Paths, "/things/{id}")
+
+	schemas := doc.Components.Schemas
+
+	t.Run("probes the validation rules", func(t *testing.T) {
+		create := schemas["CreateThing"]
+		require.NotNil(t, create)
+
+		assert.ElementsMatch(t, []string{"name", "email"}, create.Required)
+		assert.NotContains(t, create.Properties, "Hidden")
+		assert.Equal(t, "email", create.Properties["email"].Format)
+		assert.Equal(t, []any{"small", "big"}, create.Properties["kind"].Enum)
+		assert.Equal(t, []any{"a", "b", "c"}, create.Properties["tags"].Items.Enum)
+		assert.Equal(t, 16, *create.Properties["secret"].MinLength)
+		assert.Equal(t, 64, *create.Properties["secret"].MaxLength)
+		assert.Equal(t, []string{"string", "null"}, create.Properties["secret"].Type)
+
+		assert.Empty(t, schemas["UpdateThing"].Required)
+	})
+
+	t.Run("describes the responses", func(t *testing.T) {
+		assert.Equal(t, []string{"id"}, schemas["Thing"].Required)
+		assert.Contains(t, schemas, "PagedResponse_thing")
+		assert.Contains(t, schemas, "Exception")
+		assert.Contains(t, schemas, "Problem")
+
+		post := doc.Paths["/things"].Post
+		assert.Equal(t, "#/components/schemas/Thing", post.Responses["201"].Content["application/json"].Schema.Ref)
+		assert.Contains(t, post.Responses, "403")
+		assert.Equal(t, []map[string][]string{{BearerAuth: {}}}, post.Security)
+		assert.Contains(t, post.Description, "admin")
+	})
+
+	t.Run("describes the parameters", func(t *testing.T) {
+		put := doc.Paths["/things/{id}"].Put
+		require.Len(t, put.Parameters, 1)
+		assert.Equal(t, Parameter{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "string"}}, put.Parameters[0])
+		assert.Contains(t, put.Responses, "404")
+
+		get := doc.Paths["/things"].Get
+		assert.Nil(t, get.Security)
+		assert.NotContains(t, get.Responses, "401")
+		require.Len(t, get.Parameters, 2)
+		assert.Equal(t, "limit", get.Parameters[0].Name)
+		assert.Equal(t, "integer", get.Parameters[0].Schema.Type)
+	})
+}
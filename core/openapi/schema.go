@@ -0,0 +1,353 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	v "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Validator is implemented by the request DTOs, their rules are probed to describe the schema
+type Validator interface {
+	Validate(ctx context.Context) error
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	validatorType  = reflect.TypeOf((*Validator)(nil)).Elem()
+
+	// Package paths within the generic type names, e.g: PagedResponse[github.com/x/dtos.Task]
+	packagePath = regexp.MustCompile(`[\w./-]+\.`)
+)
+
+// field is a JSON property of a struct, the embedded structs are flattened
+type field struct {
+	name      string
+	index     []int
+	typ       reflect.Type
+	omitempty bool
+}
+
+func jsonFields(t reflect.Type) []field {
+	var fields []field
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			for _, embedded := range jsonFields(f.Type) {
+				embedded.index = append([]int{i}, embedded.index...)
+				fields = append(fields, embedded)
+			}
+			continue
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		fields = append(fields, field{
+			name:      name,
+			index:     []int{i},
+			typ:       f.Type,
+			omitempty: strings.Contains(opts, "omitempty"),
+		})
+	}
+
+	return fields
+}
+
+// generator builds the schemas of the Go types, the named structs become components
+type generator struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+	enums      [][]string
+}
+
+func newGenerator() *generator {
+	return &generator{
+		components: map[string]*Schema{},
+		names:      map[reflect.Type]string{},
+	}
+}
+
+func (g *generator) schema(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(g.schema(t.Elem()))
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		return g.ref(t)
+	}
+
+	// interfaces accept anything
+	return &Schema{}
+}
+
+// nullable adds "null" to the schema types, references are left as is since $ref can't have siblings
+func nullable(s *Schema) *Schema {
+	if typ, ok := s.Type.(string); ok {
+		s.Type = []string{typ, "null"}
+	}
+
+	return s
+}
+
+func (g *generator) ref(t reflect.Type) *Schema {
+	name := g.name(t)
+
+	if _, ok := g.components[name]; !ok {
+		// Registered before it's built so recursive types point to themselves
+		g.components[name] = &Schema{}
+		*g.components[name] = *g.object(t)
+	}
+
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (g *generator) name(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+
+	// PagedResponse[github.com/x/dtos.Task] becomes PagedResponse_Task
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		args := packagePath.ReplaceAllString(name[i+1:len(name)-1], "")
+		args = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r
+			}
+			return '_'
+		}, args)
+		name = name[:i] + "_" + strings.Trim(args, "_")
+	}
+
+	// Unexported types, e.g: notificationPage
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	name = string(runes)
+
+	// The same name in another package, e.g: dtos.Task and events.Task
+	for _, taken := range g.names {
+		if taken == name {
+			pkg := []rune(path.Base(t.PkgPath()))
+			pkg[0] = unicode.ToUpper(pkg[0])
+			name = string(pkg) + name
+			break
+		}
+	}
+
+	g.names[t] = name
+	return name
+}
+
+func (g *generator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	fields := jsonFields(t)
+	for _, f := range fields {
+		s.Properties[f.name] = g.schema(f.typ)
+	}
+
+	if t.Implements(validatorType) {
+		g.probe(t, s, fields)
+		return s
+	}
+
+	// The responses always have the fields without omitempty, even the nil pointers (null)
+	for _, f := range fields {
+		if !f.omitempty && f.typ.Kind() != reflect.Pointer {
+			s.Required = append(s.Required, f.name)
+		}
+	}
+
+	return s
+}
+
+// probe describes the ozzo validation rules of a request DTO by validating crafted values, since the rules can't be inspected:
+//
+//   - a field is required when it's still invalid while empty and every other field is filled;
+//   - the error of a filled field tells its format (is.Email, is.URL...), length and whether it's restricted by v.In.
+func (g *generator) probe(t reflect.Type, s *Schema, fields []field) {
+	for i, f := range fields {
+		value := reflect.New(t).Elem()
+		for j, other := range fields {
+			if i != j {
+				fill(value.FieldByIndex(other.index), "x")
+			}
+		}
+
+		if validate(value)[f.name] != nil {
+			s.Required = append(s.Required, f.name)
+		}
+
+		target := s.Properties[f.name]
+		if target.Items != nil {
+			target = target.Items
+		}
+
+		// Only the strings have formats and sets of values
+		if !isString(f.typ) {
+			continue
+		}
+
+		value = reflect.New(t).Elem()
+		fill(value.FieldByIndex(f.index), "x")
+
+		var rule v.Error
+		if !errors.As(firstError(validate(value)[f.name]), &rule) {
+			continue
+		}
+
+		switch rule.Code() {
+		case "validation_is_email":
+			target.Format = "email"
+		case "validation_is_url", "validation_is_request_url":
+			target.Format = "uri"
+		case "validation_is_uuid", "validation_is_uuid_v4":
+			target.Format = "uuid"
+		case "validation_length_too_short", "validation_length_too_long", "validation_length_out_of_range", "validation_length_invalid":
+			if min, ok := rule.Params()["min"].(int); ok && min > 0 {
+				target.MinLength = &min
+			}
+			if max, ok := rule.Params()["max"].(int); ok && max > 0 {
+				target.MaxLength = &max
+			}
+		case "validation_in_invalid":
+			target.Enum = g.enum(t, f)
+		}
+	}
+}
+
+// enum returns the largest known set of values accepted by the field
+func (g *generator) enum(t reflect.Type, f field) []any {
+	sets := append([][]string{}, g.enums...)
+	sort.SliceStable(sets, func(i, j int) bool { return len(sets[i]) > len(sets[j]) })
+
+	for _, set := range sets {
+		accepted := true
+
+		for _, candidate := range set {
+			value := reflect.New(t).Elem()
+			fill(value.FieldByIndex(f.index), candidate)
+
+			if validate(value)[f.name] != nil {
+				accepted = false
+				break
+			}
+		}
+
+		if accepted {
+			values := make([]any, 0, len(set))
+			for _, candidate := range set {
+				values = append(values, candidate)
+			}
+			return values
+		}
+	}
+
+	return nil
+}
+
+func validate(value reflect.Value) v.Errors {
+	err := value.Interface().(Validator).Validate(context.Background())
+
+	var errs v.Errors
+	if errors.As(err, &errs) {
+		return errs
+	}
+
+	return nil
+}
+
+// firstError unwraps the errors of v.Each, they're keyed by the item index
+func firstError(err error) error {
+	var errs v.Errors
+	if errors.As(err, &errs) {
+		for _, err := range errs {
+			return err
+		}
+	}
+
+	return err
+}
+
+// fill sets a non-empty value, the slices get a single item
+func fill(value reflect.Value, s string) {
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(s)
+	case reflect.Bool:
+		value.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value.SetUint(1)
+	case reflect.Float32, reflect.Float64:
+		value.SetFloat(1)
+	case reflect.Pointer:
+		item := reflect.New(value.Type().Elem())
+		fill(item.Elem(), s)
+		value.Set(item)
+	case reflect.Slice:
+		items := reflect.MakeSlice(value.Type(), 1, 1)
+		fill(items.Index(0), s)
+		value.Set(items)
+	}
+}
+
+func isString(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.String
+}
@@ -0,0 +1,43 @@
+package openapi
+
+import (
+	"bytes"
+	_ "embed"
+	"html/template"
+)
+
+// The Redoc bundle is served by the app, the docs work offline and don't depend on a CDN
+//
+//go:generate curl -fsSL -o assets/redoc.standalone.js https://cdn.redoc.ly/redoc/v2.1.3/bundles/redoc.standalone.js
+//go:embed assets/redoc.standalone.js
+var redocScript []byte
+
+var redoc = template.Must(template.New("redoc").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<title>{{ .Title }}</title>
+	<meta charset="utf-8"/>
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<style>body { margin: 0; padding: 0; }</style>
+</head>
+<body>
+	<redoc spec-url="{{ .SpecURL }}"></redoc>
+	<script src="{{ .ScriptURL }}"></script>
+</body>
+</html>
+`))
+
+// UI returns a Redoc page that renders the document served at specURL, with the Script served at scriptURL
+func UI(title, specURL, scriptURL string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := redoc.Execute(&buf, struct{ Title, SpecURL, ScriptURL string }{title, specURL, scriptURL}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Script returns the embedded Redoc bundle, run go generate ./core/openapi to update it
+func Script() []byte {
+	return redocScript
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	v "github.com/go-ozzo/ozzo-validation/v4"
+
+	"github.com/mirusky-dev/challenge-18/core/i18n"
+)
+
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// ProblemTypeBase prefixes the exception code to build the problem type, e.g: /problems/not-found
+var ProblemTypeBase = "/problems/"
+
+// Problem is the RFC 7807 representation of an Exception, it's returned when the client accepts application/problem+json
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"` // Context-ID of the request
+
+	// Extensions
+	Code   string            `json:"code"`
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+func (e *Exception) Problem(instance string) Problem {
+	problemType := "about:blank"
+	if e.Code != "" {
+		problemType = ProblemTypeBase + e.Code
+	}
+
+	return Problem{
+		Type:     problemType,
+		Title:    http.StatusText(e.Status),
+		Status:   e.Status,
+		Detail:   e.Message,
+		Instance: instance,
+		Code:     e.Code,
+		Errors:   e.Errors,
+	}
+}
+
+// WithValidation keeps the message of a validation error and, when it comes from ozzo-validation, the error of each field.
+//
+// The nested fields are joined by dots, e.g: events.0
+func WithValidation(err error) UserFriendlyExceptionOption {
+	return func(h *Exception) {
+		h.Message = err.Error()
+
+		var errs v.Errors
+		if errors.As(err, &errs) {
+			h.validation = errs
+			h.Errors = map[string]string{}
+			fieldErrors(h.Errors, "", errs, i18n.DefaultLocale)
+		}
+	}
+}
+
+// fieldErrors flattens the errors translating the ones raised by the rules, the others are kept as is
+func fieldErrors(dst map[string]string, prefix string, errs v.Errors, locale string) {
+	keys := make([]string, 0, len(errs))
+	for key := range errs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		field := strings.TrimPrefix(prefix+"."+key, ".")
+
+		var nested v.Errors
+		if errors.As(errs[key], &nested) {
+			fieldErrors(dst, field, nested, locale)
+			continue
+		}
+
+		var rule v.Error
+		if errors.As(errs[key], &rule) {
+			if message, ok := i18n.Translate(locale, "validation."+rule.Code(), rule.Params()); ok {
+				dst[field] = message
+				continue
+			}
+		}
+
+		dst[field] = errs[key].Error()
+	}
+}
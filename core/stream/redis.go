@@ -0,0 +1,85 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/mirusky-dev/challenge-18/core"
+)
+
+// DefaultChannel is the Redis pub/sub channel shared by the API replicas
+const DefaultChannel = "gobp.stream"
+
+type redisBroker struct {
+	client  *redis.Client
+	channel string
+	pubsub  *redis.PubSub
+
+	clock core.Clock
+	ids   core.IDGenerator
+
+	// local fans out the messages received from Redis to the subscribers of this replica
+	local Broker
+}
+
+// NewRedisBroker creates a broker that publishes through Redis pub/sub, so every replica receives every message.
+//
+// Each replica keeps a single Redis subscription and fans it out locally, regardless of the number of clients.
+func NewRedisBroker(ctx context.Context, client *redis.Client, channel string, clock core.Clock, ids core.IDGenerator) (Broker, error) {
+	pubsub := client.Subscribe(ctx, channel)
+
+	// Waits for the subscription confirmation, so a broken connection is reported right away
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	b := &redisBroker{
+		client:  client,
+		channel: channel,
+		pubsub:  pubsub,
+		clock:   clock,
+		ids:     ids,
+		local:   NewMemoryBroker(clock, ids),
+	}
+
+	go b.relay()
+
+	return b, nil
+}
+
+func (b *redisBroker) relay() {
+	for payload := range b.pubsub.Channel() {
+		var msg Message
+		if err := json.Unmarshal([]byte(payload.Payload), &msg); err != nil {
+			slog.Warn("stream: invalid message", "channel", b.channel, "error", err)
+			continue
+		}
+
+		b.local.Publish(context.Background(), msg)
+	}
+}
+
+func (b *redisBroker) Publish(ctx context.Context, msg Message) error {
+	// Stamped before sending, so every replica delivers the same ID
+	payload, err := json.Marshal(stamp(msg, b.clock, b.ids))
+	if err != nil {
+		return err
+	}
+
+	return b.client.Publish(ctx, b.channel, payload).Err()
+}
+
+func (b *redisBroker) Subscribe() (<-chan Message, func()) {
+	return b.local.Subscribe()
+}
+
+func (b *redisBroker) Close() error {
+	err := b.pubsub.Close()
+	b.local.Close()
+
+	return err
+}
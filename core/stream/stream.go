@@ -0,0 +1,150 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/mirusky-dev/challenge-18/core"
+)
+
+// subscriberBuffer is how many messages a slow subscriber can fall behind before new ones are dropped for it
+const subscriberBuffer = 64
+
+// Message is a real-time event pushed to the connected clients
+type Message struct {
+	ID         string          `json:"id"`
+	Event      string          `json:"event"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Data       json.RawMessage `json:"data"`
+
+	// OwnerID is the user the event belongs to, it's used to apply the visibility rules
+	OwnerID string `json:"ownerId"`
+}
+
+// NewMessage creates a message, the ID and the time are set by the broker on Publish
+func NewMessage(event, ownerID string, data any) (Message, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		Event:   event,
+		Data:    b,
+		OwnerID: ownerID,
+	}, nil
+}
+
+// VisibleTo applies the same rule as the task repository, managers see everything and the others only what they own
+func (m Message) VisibleTo(ctx core.Ctx) bool {
+	if slices.Contains(ctx.Roles(), "manager") {
+		return true
+	}
+
+	return m.OwnerID != "" && m.OwnerID == ctx.UserID()
+}
+
+type Broker interface {
+	// Publish sends the message to every subscriber, including the ones connected to other replicas when supported
+	Publish(ctx context.Context, msg Message) error
+
+	// Subscribe returns a channel with the published messages and a function to stop receiving them
+	Subscribe() (<-chan Message, func())
+
+	// Close stops the broker and closes every subscription
+	Close() error
+}
+
+// stamp sets the ID and the time of a message that doesn't have them yet
+func stamp(msg Message, clock core.Clock, ids core.IDGenerator) Message {
+	if msg.ID == "" {
+		msg.ID = ids.NewID()
+	}
+
+	if msg.OccurredAt.IsZero() {
+		msg.OccurredAt = clock.Now().UTC()
+	}
+
+	return msg
+}
+
+type memoryBroker struct {
+	mu          sync.RWMutex
+	subscribers map[chan Message]struct{}
+	closed      bool
+
+	clock core.Clock
+	ids   core.IDGenerator
+}
+
+// NewMemoryBroker creates a broker that only reaches the subscribers of the current process
+func NewMemoryBroker(clock core.Clock, ids core.IDGenerator) Broker {
+	return &memoryBroker{
+		subscribers: map[chan Message]struct{}{},
+		clock:       clock,
+		ids:         ids,
+	}
+}
+
+func (b *memoryBroker) Publish(ctx context.Context, msg Message) error {
+	msg = stamp(msg, b.clock, b.ids)
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- msg:
+		default:
+			// A slow client shouldn't hold everyone else
+		}
+	}
+
+	return nil
+}
+
+func (b *memoryBroker) Subscribe() (<-chan Message, func()) {
+	ch := make(chan Message, subscriberBuffer)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	b.subscribers[ch] = struct{}{}
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			if _, ok := b.subscribers[ch]; ok {
+				delete(b.subscribers, ch)
+				close(ch)
+			}
+		})
+	}
+
+	return ch, unsubscribe
+}
+
+func (b *memoryBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for ch := range b.subscribers {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+
+	return nil
+}
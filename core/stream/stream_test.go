@@ -0,0 +1,72 @@
+package stream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirusky-dev/challenge-18/core"
+)
+
+func TestMemoryBroker(t *testing.T) {
+	broker := NewMemoryBroker(core.NewSystemClock(), core.NewUUIDv7Generator())
+	defer broker.Close()
+
+	first, unsubscribeFirst := broker.Subscribe()
+	second, unsubscribeSecond := broker.Subscribe()
+	defer unsubscribeSecond()
+
+	msg, err := NewMessage("task.created", "tech-1", map[string]string{"id": "task-1"})
+	require.NoError(t, err)
+	require.NoError(t, broker.Publish(context.Background(), msg))
+
+	var ids []string
+	for _, ch := range []<-chan Message{first, second} {
+		select {
+		case got := <-ch:
+			ids = append(ids, got.ID)
+			assert.False(t, got.OccurredAt.IsZero(), "the broker should set the time")
+			assert.JSONEq(t, `{"id":"task-1"}`, string(got.Data))
+		case <-time.After(time.Second):
+			t.Fatal("message not received")
+		}
+	}
+
+	assert.NotEmpty(t, ids[0], "the broker should set the ID")
+	assert.Equal(t, ids[0], ids[1], "every subscriber should receive the same ID")
+
+	unsubscribeFirst()
+	unsubscribeFirst()
+
+	_, ok := <-first
+	assert.False(t, ok, "channel should be closed after unsubscribe")
+
+	require.NoError(t, broker.Publish(context.Background(), msg))
+	assert.Len(t, second, 1)
+}
+
+func TestMemoryBroker_SlowSubscriber(t *testing.T) {
+	broker := NewMemoryBroker(core.NewSystemClock(), core.NewUUIDv7Generator())
+	defer broker.Close()
+
+	ch, unsubscribe := broker.Subscribe()
+	defer unsubscribe()
+
+	for i := 0; i < subscriberBuffer*2; i++ {
+		require.NoError(t, broker.Publish(context.Background(), Message{ID: "id"}))
+	}
+
+	assert.Len(t, ch, subscriberBuffer)
+}
+
+func TestMessage_VisibleTo(t *testing.T) {
+	msg := Message{OwnerID: "tech-1"}
+
+	assert.True(t, msg.VisibleTo(core.NewUserCtx("manager-1", []string{"manager"}, nil)))
+	assert.True(t, msg.VisibleTo(core.NewUserCtx("tech-1", []string{"tech"}, nil)))
+	assert.False(t, msg.VisibleTo(core.NewUserCtx("tech-2", []string{"tech"}, nil)))
+	assert.False(t, Message{}.VisibleTo(core.NewUserCtx("", []string{"tech"}, nil)))
+}
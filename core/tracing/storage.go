@@ -0,0 +1,89 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ContextStorage is a fiber.Storage that can trace its operations as children of a context span
+type ContextStorage interface {
+	fiber.Storage
+	WithContext(ctx context.Context) fiber.Storage
+}
+
+// StorageContext binds the storage to ctx when it supports it
+func StorageContext(ctx context.Context, storage fiber.Storage) fiber.Storage {
+	if cs, ok := storage.(ContextStorage); ok {
+		return cs.WithContext(ctx)
+	}
+
+	return storage
+}
+
+// storage traces the operations of a fiber.Storage. The keys aren't recorded, most of them are tokens.
+type storage struct {
+	fiber.Storage
+	name string
+	ctx  context.Context
+}
+
+// NewStorage wraps the storage, the name tells the storages apart in the spans, e.g: refresh-token
+func NewStorage(name string, s fiber.Storage) ContextStorage {
+	return &storage{
+		Storage: s,
+		name:    name,
+		ctx:     context.Background(),
+	}
+}
+
+func (s *storage) WithContext(ctx context.Context) fiber.Storage {
+	bound := *s
+	bound.ctx = ctx
+	return &bound
+}
+
+func (s *storage) start(operation string) trace.Span {
+	_, span := Start(s.ctx, "storage."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "redis"),
+			attribute.String("db.operation", operation),
+			attribute.String("storage.name", s.name),
+		),
+	)
+
+	return span
+}
+
+func (s *storage) Get(key string) ([]byte, error) {
+	span := s.start("get")
+	value, err := s.Storage.Get(key)
+	span.SetAttributes(attribute.Bool("storage.hit", value != nil))
+	End(span, err)
+	return value, err
+}
+
+func (s *storage) Set(key string, val []byte, exp time.Duration) error {
+	span := s.start("set")
+	err := s.Storage.Set(key, val, exp)
+	End(span, err)
+	return err
+}
+
+func (s *storage) Delete(key string) error {
+	span := s.start("delete")
+	err := s.Storage.Delete(key)
+	End(span, err)
+	return err
+}
+
+func (s *storage) Reset() error {
+	span := s.start("reset")
+	err := s.Storage.Reset()
+	End(span, err)
+	return err
+}
@@ -0,0 +1,92 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mirusky-dev/challenge-18/core/env"
+)
+
+// record installs a provider that keeps the ended spans in memory
+func record(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	return recorder
+}
+
+func TestInjectExtract(t *testing.T) {
+	record(t)
+
+	assert.Nil(t, Inject(context.Background()), "nothing to propagate without a span")
+
+	ctx, span := Start(context.Background(), "request")
+	defer span.End()
+
+	carrier := Inject(ctx)
+	require.Contains(t, carrier, "traceparent")
+
+	extracted := trace.SpanContextFromContext(Extract(context.Background(), carrier))
+	assert.Equal(t, span.SpanContext().TraceID(), extracted.TraceID())
+	assert.Equal(t, span.SpanContext().SpanID(), extracted.SpanID())
+}
+
+type mapStorage map[string][]byte
+
+func (s mapStorage) Get(key string) ([]byte, error) { return s[key], nil }
+func (s mapStorage) Set(key string, val []byte, exp time.Duration) error {
+	s[key] = val
+	return nil
+}
+func (s mapStorage) Delete(key string) error { delete(s, key); return nil }
+func (s mapStorage) Reset() error            { return nil }
+func (s mapStorage) Close() error            { return nil }
+
+var _ fiber.Storage = mapStorage{}
+
+func TestStorage(t *testing.T) {
+	recorder := record(t)
+
+	ctx, parent := Start(context.Background(), "request")
+	storage := NewStorage("refresh-token", mapStorage{})
+
+	require.NoError(t, StorageContext(ctx, storage).Set("token", []byte("user"), time.Minute))
+	value, err := StorageContext(ctx, storage).Get("token")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("user"), value)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, "storage.set", spans[0].Name())
+	assert.Equal(t, "storage.get", spans[1].Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[1].Parent().SpanID())
+
+	for _, attr := range spans[1].Attributes() {
+		assert.NotEqual(t, "token", attr.Value.AsString(), "the keys must not be recorded")
+	}
+}
+
+func TestSetup(t *testing.T) {
+	shutdown, err := Setup(context.Background(), env.Config{TracingExporter: ExporterNone}, "test")
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+
+	_, err = Setup(context.Background(), env.Config{TracingExporter: "zipkin"}, "test")
+	assert.Error(t, err)
+}
@@ -0,0 +1,87 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSender_Send(t *testing.T) {
+	const secret = "s3cr3t"
+
+	var received *http.Request
+	var receivedBody []byte
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		receivedBody, _ = io.ReadAll(r.Body)
+
+		timestamp, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		if !Verify(secret, timestamp, receivedBody, r.Header.Get(HeaderSignature)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer receiver.Close()
+
+	sender := NewSender(5 * time.Second)
+
+	tests := []struct {
+		name       string
+		secret     string
+		wantStatus int
+		wantErr    bool
+	}{
+		{name: "valid signature", secret: secret, wantStatus: http.StatusOK},
+		{name: "wrong secret", secret: "another", wantStatus: http.StatusUnauthorized, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := sender.Send(context.Background(), Request{
+				URL:        receiver.URL,
+				Secret:     tt.secret,
+				Event:      "task.created",
+				DeliveryID: "delivery-1",
+				Body:       []byte(`{"event":"task.created"}`),
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Sender.Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if res.StatusCode != tt.wantStatus {
+				t.Errorf("Sender.Send() status = %d, want %d", res.StatusCode, tt.wantStatus)
+			}
+
+			if received.Header.Get(HeaderEvent) != "task.created" || received.Header.Get(HeaderDelivery) != "delivery-1" {
+				t.Errorf("missing webhook headers: %v", received.Header)
+			}
+
+			if string(receivedBody) != `{"event":"task.created"}` {
+				t.Errorf("receiver body = %s", receivedBody)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{}`)
+	signature := Sign("secret", 1700000000, body)
+
+	if !Verify("secret", 1700000000, body, signature) {
+		t.Error("Verify() = false for a valid signature")
+	}
+
+	if Verify("secret", 1700000001, body, signature) {
+		t.Error("Verify() = true for another timestamp")
+	}
+
+	if Verify("secret", 1700000000, body, signature[len(signaturePrefix):]) {
+		t.Error("Verify() = true without the sha256= prefix")
+	}
+}
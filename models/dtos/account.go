@@ -4,6 +4,7 @@ import (
 	"context"
 
 	v "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/mirusky-dev/challenge-18/models/entities"
 )
 
 type ChangePassword struct {
@@ -15,3 +16,23 @@ func (s ChangePassword) Validate(ctx context.Context) error {
 		v.Field(&s.Password, v.Required),
 	)
 }
+
+type ChangeLocale struct {
+	Locale string `json:"locale"`
+}
+
+func (s ChangeLocale) Validate(ctx context.Context) error {
+	return v.ValidateStruct(&s,
+		v.Field(&s.Locale, v.Required),
+	)
+}
+
+type ChangeDigestFrequency struct {
+	Frequency string `json:"frequency"`
+}
+
+func (s ChangeDigestFrequency) Validate(ctx context.Context) error {
+	return v.ValidateStruct(&s,
+		v.Field(&s.Frequency, v.Required, v.In(entities.DigestImmediate, entities.DigestDaily, entities.DigestWeekly)),
+	)
+}
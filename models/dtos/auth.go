@@ -8,6 +8,14 @@ import (
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 )
 
+// Token is the response of the login and the refresh, the refresh token is also set as a cookie
+type Token struct {
+	Token            string    `json:"token"`
+	ExpiresAt        int64     `json:"expiresAt"` // unix seconds
+	RefreshToken     string    `json:"refreshToken"`
+	RefreshExpiresAt time.Time `json:"refreshExpiresAt"`
+}
+
 type Login struct {
 	UsernameOrEmail string `json:"usernameOrEmail"`
 	Password        string `json:"password"`
@@ -0,0 +1,10 @@
+package dtos
+
+type DeadLetterMail struct {
+	ID           string   `json:"id"`
+	To           []string `json:"to"`
+	Subject      string   `json:"subject"`
+	Retried      int      `json:"retried"`
+	LastError    string   `json:"lastError"`
+	LastFailedAt string   `json:"lastFailedAt"`
+}
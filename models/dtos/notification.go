@@ -0,0 +1,81 @@
+package dtos
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	v "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/mirusky-dev/challenge-18/core/background/events"
+	"github.com/mirusky-dev/challenge-18/models/entities"
+)
+
+// NotificationEvents are the event types a user can set preferences for
+var NotificationEvents = []string{
+	events.TypeTaskCreated,
+	events.TypeTaskCompleted,
+	events.TypeTaskDeleted,
+}
+
+type Notification struct {
+	ID      string          `json:"id"`
+	Event   string          `json:"event"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+	Read    bool            `json:"read"`
+
+	ReadAt    *string `json:"readAt"`
+	CreatedAt *string `json:"createdAt"`
+}
+
+func (n *Notification) FromEntity(e entities.Notification) {
+	n.ID = e.ID
+	n.Event = e.Event
+	n.Message = e.Message
+	n.Read = e.ReadAt != nil
+
+	if e.Data != "" {
+		n.Data = json.RawMessage(e.Data)
+	}
+
+	if e.ReadAt != nil {
+		formated := e.ReadAt.Format(time.RFC3339)
+		n.ReadAt = &formated
+	}
+
+	if !e.CreatedAt.IsZero() {
+		formated := e.CreatedAt.Format(time.RFC3339)
+		n.CreatedAt = &formated
+	}
+}
+
+type NotificationPreference struct {
+	Event string `json:"event"`
+	Email bool   `json:"email"`
+	InApp bool   `json:"inApp"`
+}
+
+func (p *NotificationPreference) FromEntity(e entities.NotificationPreference) {
+	p.Event = e.Event
+	p.Email = e.WantsEmail()
+	p.InApp = e.WantsInApp()
+}
+
+type UpdateNotificationPreference struct {
+	Event string `json:"-"`
+	Email *bool  `json:"email"`
+	InApp *bool  `json:"inApp"`
+}
+
+func (s UpdateNotificationPreference) Validate(ctx context.Context) error {
+	allowed := make([]any, 0, len(NotificationEvents))
+	for _, event := range NotificationEvents {
+		allowed = append(allowed, event)
+	}
+
+	return v.ValidateStruct(&s,
+		v.Field(&s.Event, v.Required, v.In(allowed...)),
+		v.Field(&s.Email, v.When(s.InApp == nil, v.NotNil)),
+		v.Field(&s.InApp, v.When(s.Email == nil, v.NotNil)),
+	)
+}
@@ -10,16 +10,18 @@ import (
 )
 
 type User struct {
-	ID              string
-	Username        string
-	Email           string
-	IsEmailVerified *bool
-	Role            string
-	ManagerID       *string
+	ID              string  `json:"id"`
+	Username        string  `json:"username"`
+	Email           string  `json:"email"`
+	IsEmailVerified *bool   `json:"isEmailVerified"`
+	Role            string  `json:"role"`
+	Locale          string  `json:"locale"`
+	DigestFrequency string  `json:"digestFrequency"`
+	ManagerID       *string `json:"managerId"`
 
-	CreatedAt *string
-	UpdatedAt *string
-	DeletedAt *string
+	CreatedAt *string `json:"createdAt"`
+	UpdatedAt *string `json:"updatedAt"`
+	DeletedAt *string `json:"deletedAt"`
 }
 
 func (u *User) FromEntity(e entities.User) {
@@ -28,6 +30,8 @@ func (u *User) FromEntity(e entities.User) {
 	u.Email = e.Email
 	u.IsEmailVerified = e.IsEmailVerified
 	u.Role = e.Role
+	u.Locale = e.Locale
+	u.DigestFrequency = e.DigestFrequency
 	u.ManagerID = e.ManagerID
 
 	if !e.CreatedAt.IsZero() {
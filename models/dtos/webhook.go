@@ -0,0 +1,125 @@
+package dtos
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	v "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/mirusky-dev/challenge-18/core/background/events"
+	"github.com/mirusky-dev/challenge-18/models/entities"
+)
+
+// WebhookAllEvents subscribes a webhook to every event type
+const WebhookAllEvents = "*"
+
+type Webhook struct {
+	ID     string   `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Active bool     `json:"active"`
+
+	// Secret is only returned when the webhook is created
+	Secret string `json:"secret,omitempty"`
+
+	CreatedAt *string `json:"createdAt"`
+	UpdatedAt *string `json:"updatedAt"`
+}
+
+func (w *Webhook) FromEntity(e entities.Webhook) {
+	w.ID = e.ID
+	w.URL = e.URL
+	w.Events = strings.Split(e.Events, ",")
+	w.Active = e.Active == nil || *e.Active
+
+	if !e.CreatedAt.IsZero() {
+		formated := e.CreatedAt.Format(time.RFC3339)
+		w.CreatedAt = &formated
+	}
+
+	if !e.UpdatedAt.IsZero() {
+		formated := e.UpdatedAt.Format(time.RFC3339)
+		w.UpdatedAt = &formated
+	}
+}
+
+type WebhookDelivery struct {
+	ID         string `json:"id"`
+	WebhookID  string `json:"webhookId"`
+	Event      string `json:"event"`
+	Payload    string `json:"payload"`
+	Attempts   int    `json:"attempts"`
+	StatusCode int    `json:"statusCode"`
+	Response   string `json:"response"`
+	Error      string `json:"error"`
+
+	DeliveredAt *string `json:"deliveredAt"`
+	CreatedAt   *string `json:"createdAt"`
+	UpdatedAt   *string `json:"updatedAt"`
+}
+
+func (d *WebhookDelivery) FromEntity(e entities.WebhookDelivery) {
+	d.ID = e.ID
+	d.WebhookID = e.WebhookID
+	d.Event = e.Event
+	d.Payload = e.Payload
+	d.Attempts = e.Attempts
+	d.StatusCode = e.StatusCode
+	d.Response = e.Response
+	d.Error = e.Error
+
+	if e.DeliveredAt != nil {
+		formated := e.DeliveredAt.Format(time.RFC3339)
+		d.DeliveredAt = &formated
+	}
+
+	if !e.CreatedAt.IsZero() {
+		formated := e.CreatedAt.Format(time.RFC3339)
+		d.CreatedAt = &formated
+	}
+
+	if !e.UpdatedAt.IsZero() {
+		formated := e.UpdatedAt.Format(time.RFC3339)
+		d.UpdatedAt = &formated
+	}
+}
+
+func webhookEventRules() []v.Rule {
+	allowed := []any{WebhookAllEvents}
+	for _, event := range events.WebhookEvents {
+		allowed = append(allowed, event)
+	}
+
+	return []v.Rule{v.Each(v.Required, v.In(allowed...))}
+}
+
+type CreateWebhook struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`
+}
+
+func (s CreateWebhook) Validate(ctx context.Context) error {
+	return v.ValidateStruct(&s,
+		v.Field(&s.URL, v.Required, is.URL),
+		v.Field(&s.Secret, v.Length(16, 0)),
+		v.Field(&s.Events, append([]v.Rule{v.Required}, webhookEventRules()...)...),
+	)
+}
+
+type UpdateWebhook struct {
+	URL    *string  `json:"url"`
+	Secret *string  `json:"secret"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`
+}
+
+func (s UpdateWebhook) Validate(ctx context.Context) error {
+	return v.ValidateStruct(&s,
+		v.Field(&s.URL, v.NilOrNotEmpty, is.URL),
+		v.Field(&s.Secret, v.NilOrNotEmpty, v.Length(16, 0)),
+		v.Field(&s.Events, webhookEventRules()...),
+	)
+}
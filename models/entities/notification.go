@@ -0,0 +1,37 @@
+package entities
+
+import "time"
+
+type Notification struct {
+	ID      string `gorm:"type:text;primarykey"`
+	UserID  string `gorm:"type:text"`
+	Event   string `gorm:"type:text"`
+	Message string `gorm:"type:text"`
+	Data    string `gorm:"type:text"` // JSON with the event details, e.g: the task id
+
+	ReadAt    *time.Time `gorm:"type:timestamp"`
+	CreatedAt time.Time  `gorm:"type:timestamp"`
+	UpdatedAt time.Time  `gorm:"type:timestamp"`
+}
+
+// NotificationPreference holds the channels a user wants to be notified through for an event type,
+// when there is no preference every channel is enabled
+type NotificationPreference struct {
+	UserID string `gorm:"type:text;primarykey"`
+	Event  string `gorm:"type:text;primarykey"`
+	Email  *bool  `gorm:"type:bool;default:true"`
+	InApp  *bool  `gorm:"type:bool;default:true"`
+
+	CreatedAt time.Time `gorm:"type:timestamp"`
+	UpdatedAt time.Time `gorm:"type:timestamp"`
+}
+
+// WantsEmail reports if the user should receive the event by email
+func (p NotificationPreference) WantsEmail() bool {
+	return p.Email == nil || *p.Email
+}
+
+// WantsInApp reports if the user should receive the event in the notification center
+func (p NotificationPreference) WantsInApp() bool {
+	return p.InApp == nil || *p.InApp
+}
@@ -3,7 +3,6 @@ package entities
 import (
 	"time"
 
-	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -17,11 +16,3 @@ type Task struct {
 	UpdatedAt   time.Time      `gorm:"type:timestamp"`
 	DeletedAt   gorm.DeletedAt `gorm:"type:timestamp;index"`
 }
-
-func (t *Task) BeforeCreate(tx *gorm.DB) (err error) {
-	if t.ID == "" {
-		t.ID = uuid.New().String()
-	}
-
-	return nil
-}
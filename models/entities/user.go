@@ -14,6 +14,8 @@ type User struct {
 	Password        string `gorm:"type:text"`
 	IsEmailVerified *bool  `gorm:"type:bool;default:false"`
 	Role            string `gorm:"type:text"`
+	Locale          string `gorm:"type:text"`
+	DigestFrequency string `gorm:"type:text"` // immediate, daily or weekly, empty means immediate
 	Signature       string `gorm:"type:text"` // https://medium.com/swlh/building-a-user-auth-system-with-jwt-using-golang-30892659cc0#06a3
 
 	CreatedAt time.Time      `gorm:"type:timestamp"`
@@ -25,13 +27,22 @@ type User struct {
 	Tasks     []Task
 }
 
+// BeforeCreate sets a random signature, unlike the ID it's a secret that must not be guessed
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
-	if u.ID == "" {
-		u.ID = uuid.New().String()
-	}
 	if u.Signature == "" {
 		u.Signature = uuid.New().String()
 	}
 
 	return nil
 }
+
+const (
+	DigestImmediate = "immediate"
+	DigestDaily     = "daily"
+	DigestWeekly    = "weekly"
+)
+
+// WantsDigest reports if the task emails should be grouped in a periodic digest instead of sent right away
+func (u User) WantsDigest() bool {
+	return u.DigestFrequency == DigestDaily || u.DigestFrequency == DigestWeekly
+}
@@ -0,0 +1,34 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Webhook struct {
+	ID     string `gorm:"type:text;primarykey"`
+	URL    string `gorm:"type:text"`
+	Secret string `gorm:"type:text"`
+	Events string `gorm:"type:text"` // Comma separated event types, * subscribes to every event
+	Active *bool  `gorm:"type:bool;default:true"`
+
+	CreatedAt time.Time      `gorm:"type:timestamp"`
+	UpdatedAt time.Time      `gorm:"type:timestamp"`
+	DeletedAt gorm.DeletedAt `gorm:"type:timestamp;index"`
+}
+
+type WebhookDelivery struct {
+	ID         string `gorm:"type:text;primarykey"`
+	WebhookID  string `gorm:"type:text"`
+	Event      string `gorm:"type:text"`
+	Payload    string `gorm:"type:text"`
+	Attempts   int
+	StatusCode int
+	Response   string `gorm:"type:text"`
+	Error      string `gorm:"type:text"`
+
+	DeliveredAt *time.Time `gorm:"type:timestamp"`
+	CreatedAt   time.Time  `gorm:"type:timestamp"`
+	UpdatedAt   time.Time  `gorm:"type:timestamp"`
+}
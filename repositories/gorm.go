@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/mirusky-dev/challenge-18/core"
+)
+
+// Scope prepares the queries of a GormRepository, e.g: to keep the entities visible by the user in ctx
+type Scope func(ctx context.Context, db *gorm.DB) *gorm.DB
+
+// GormRepository implements core.IBaseRepository for the entities with a string ID, the entity repositories embed it
+// and add their own queries. The scopes apply to every method but Create.
+type GormRepository[T any] struct {
+	db     *gorm.DB
+	scopes []Scope
+}
+
+func NewGormRepository[T any](db *gorm.DB, scopes ...Scope) *GormRepository[T] {
+	return &GormRepository[T]{db: db, scopes: scopes}
+}
+
+// Query starts a query on the entities with the scopes applied, within the unit of work of ctx if there is one
+func (r *GormRepository[T]) Query(ctx context.Context) *gorm.DB {
+	query := conn(ctx, r.db).Model(new(T))
+	for _, scope := range r.scopes {
+		query = scope(ctx, query)
+	}
+
+	return query
+}
+
+func (r *GormRepository[T]) Create(ctx context.Context, entity T) (T, *core.Exception) {
+	if err := conn(ctx, r.db).Create(&entity).Error; err != nil {
+		var zero T
+		return zero, TranslateError(err)
+	}
+
+	return entity, nil
+}
+
+func (r *GormRepository[T]) GetByID(ctx context.Context, id string) (T, *core.Exception) {
+	var entity T
+	if err := r.Query(ctx).First(&entity, "id = ?", id).Error; err != nil {
+		var zero T
+		return zero, TranslateError(err)
+	}
+
+	return entity, nil
+}
+
+func (r *GormRepository[T]) GetAll(ctx context.Context, limit, offset int) ([]T, int, *core.Exception) {
+	var total int64
+	if err := r.Query(ctx).Count(&total).Error; err != nil {
+		return nil, 0, TranslateError(err)
+	}
+
+	entities := []T{}
+	if err := r.Query(ctx).Limit(limit).Offset(offset).Find(&entities).Error; err != nil {
+		return nil, 0, TranslateError(err)
+	}
+
+	return entities, int(total), nil
+}
+
+// Update saves the non-zero fields of changes and returns the updated entity, or core.NotFound when it isn't in the
+// scopes
+func (r *GormRepository[T]) Update(ctx context.Context, id string, changes T) (T, *core.Exception) {
+	var zero T
+
+	if err := r.Query(ctx).Where("id = ?", id).Updates(&changes).Error; err != nil {
+		return zero, TranslateError(err)
+	}
+
+	// MySQL counts the changed rows, not the matched ones, so RowsAffected can't tell a missing entity from an update
+	// that didn't change anything
+	return r.GetByID(ctx, id)
+}
+
+func (r *GormRepository[T]) Delete(ctx context.Context, id string) *core.Exception {
+	result := r.Query(ctx).Where("id = ?", id).Delete(new(T))
+	if result.Error != nil {
+		return TranslateError(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return core.NotFound()
+	}
+
+	return nil
+}
+
+// TranslateError maps a gorm error to an exception, the duplicated keys and foreign key violations are only told apart
+// when the gorm TranslateError config is enabled
+func TranslateError(err error) *core.Exception {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return core.NotFound()
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return core.Conflict(core.WithError(err))
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		// Either the referenced entity doesn't exist or the deleted one is still referenced
+		return core.Conflict(core.WithError(err))
+	default:
+		return core.Unexpected(core.WithError(err))
+	}
+}
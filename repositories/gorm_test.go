@@ -0,0 +1,84 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/models/entities"
+	"github.com/mirusky-dev/challenge-18/repositories"
+	"github.com/mirusky-dev/challenge-18/testkit"
+	"github.com/mirusky-dev/challenge-18/testkit/integration"
+)
+
+func TestGormRepository(t *testing.T) {
+	db := integration.Open(t, testkit.NewIDs())
+	repository := repositories.NewGormRepository[entities.Webhook](db)
+	ctx := context.Background()
+
+	webhook, exception := repository.Create(ctx, entities.Webhook{URL: "http://localhost:9000/hooks", Events: "*"})
+	require.Nil(t, exception)
+	assert.NotEmpty(t, webhook.ID)
+
+	_, exception = repository.Create(ctx, entities.Webhook{ID: webhook.ID, URL: "http://localhost:9000/other"})
+	require.NotNil(t, exception)
+	assert.Equal(t, core.CodeConflict, exception.Code, "duplicated ID")
+
+	inactive := false
+	updated, exception := repository.Update(ctx, webhook.ID, entities.Webhook{Active: &inactive})
+	require.Nil(t, exception)
+	assert.False(t, *updated.Active)
+	assert.Equal(t, "http://localhost:9000/hooks", updated.URL, "the zero fields aren't changed")
+
+	_, exception = repository.Update(ctx, "missing", entities.Webhook{URL: "http://localhost:9000/hooks"})
+	require.NotNil(t, exception)
+	assert.Equal(t, core.CodeNotFound, exception.Code)
+
+	items, total, exception := repository.GetAll(ctx, 10, 0)
+	require.Nil(t, exception)
+	assert.Equal(t, 1, total)
+	assert.Len(t, items, 1)
+
+	require.Nil(t, repository.Delete(ctx, webhook.ID))
+
+	exception = repository.Delete(ctx, webhook.ID)
+	require.NotNil(t, exception)
+	assert.Equal(t, core.CodeNotFound, exception.Code, "already deleted")
+
+	_, exception = repository.GetByID(ctx, webhook.ID)
+	require.NotNil(t, exception)
+	assert.Equal(t, core.CodeNotFound, exception.Code)
+}
+
+func TestTaskVisibilityScope(t *testing.T) {
+	db := integration.Open(t, testkit.NewIDs())
+	tasks := repositories.NewTaskRepository(db)
+
+	manager := core.NewContext(context.Background(), core.NewUserCtx("manager", []string{"manager"}, []string{}))
+	tech1 := core.NewContext(context.Background(), core.NewUserCtx("tech1", []string{"tech"}, []string{}))
+	tech2 := core.NewContext(context.Background(), core.NewUserCtx("tech2", []string{"tech"}, []string{}))
+
+	task, exception := tasks.Create(tech1, entities.Task{Summary: "Fix the printer", UserID: "tech1"})
+	require.Nil(t, exception)
+
+	_, exception = tasks.GetByID(tech2, task.ID)
+	assert.Equal(t, core.CodeNotFound, exception.Code)
+
+	_, exception = tasks.Update(tech2, task.ID, entities.Task{Summary: "Hijacked"})
+	assert.Equal(t, core.CodeNotFound, exception.Code)
+
+	assert.Equal(t, core.CodeNotFound, tasks.Delete(tech2, task.ID).Code)
+
+	_, total, exception := tasks.GetAll(tech2, 10, 0)
+	require.Nil(t, exception)
+	assert.Zero(t, total)
+
+	found, exception := tasks.GetByID(manager, task.ID)
+	require.Nil(t, exception)
+	assert.Equal(t, "Fix the printer", found.Summary, "the update of tech2 didn't apply")
+
+	require.Nil(t, tasks.Delete(manager, task.ID))
+}
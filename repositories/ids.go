@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"github.com/mirusky-dev/challenge-18/core"
+)
+
+// IDPlugin sets the ID of the entities created without one, from the generator given to NewIDPlugin
+type IDPlugin struct {
+	ids core.IDGenerator
+}
+
+func NewIDPlugin(ids core.IDGenerator) gorm.Plugin {
+	return IDPlugin{ids: ids}
+}
+
+func (IDPlugin) Name() string {
+	return "ids"
+}
+
+func (p IDPlugin) Initialize(db *gorm.DB) error {
+	return db.Callback().Create().Before("gorm:before_create").Register("ids:before_create", p.setIDs)
+}
+
+func (p IDPlugin) setIDs(db *gorm.DB) {
+	if db.Statement.Schema == nil {
+		return
+	}
+
+	// The entities without a string ID, e.g: the notification preferences, have a composite key
+	field := db.Statement.Schema.LookUpField("ID")
+	if field == nil || field.FieldType.Kind() != reflect.String {
+		return
+	}
+
+	ctx := db.Statement.Context
+	value := db.Statement.ReflectValue
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			p.setID(ctx, field, reflect.Indirect(value.Index(i)))
+		}
+	case reflect.Struct:
+		p.setID(ctx, field, value)
+	}
+}
+
+func (p IDPlugin) setID(ctx context.Context, field *schema.Field, value reflect.Value) {
+	if _, zero := field.ValueOf(ctx, value); zero {
+		_ = field.Set(ctx, value, p.ids.NewID())
+	}
+}
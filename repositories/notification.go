@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/models/entities"
+
+	"gorm.io/gorm"
+)
+
+type INotificationRepository interface {
+	Create(ctx context.Context, entity entities.Notification) (entities.Notification, *core.Exception)
+
+	// GetAll returns the notifications of the user, newest first
+	GetAll(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]entities.Notification, int, *core.Exception)
+	CountUnread(ctx context.Context, userID string) (int, *core.Exception)
+	MarkRead(ctx context.Context, userID, id string) *core.Exception
+	MarkAllRead(ctx context.Context, userID string) *core.Exception
+
+	// GetPreference returns the user preference for the event, or the default one when it was never changed
+	GetPreference(ctx context.Context, userID, event string) (entities.NotificationPreference, *core.Exception)
+	GetPreferences(ctx context.Context, userID string) ([]entities.NotificationPreference, *core.Exception)
+	SavePreference(ctx context.Context, preference entities.NotificationPreference) (entities.NotificationPreference, *core.Exception)
+}
+
+type gormNotificationRepository struct {
+	db    *gorm.DB
+	clock core.Clock
+}
+
+func NewNotificationRepository(db *gorm.DB, clock core.Clock) INotificationRepository {
+	return &gormNotificationRepository{db, clock}
+}
+
+func (r *gormNotificationRepository) Create(ctx context.Context, entity entities.Notification) (entities.Notification, *core.Exception) {
+	err := conn(ctx, r.db).Create(&entity).Error
+	if err != nil {
+		return entities.Notification{}, TranslateError(err)
+	}
+
+	return entity, nil
+}
+
+func (r *gormNotificationRepository) GetAll(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]entities.Notification, int, *core.Exception) {
+	var notifications []entities.Notification
+	var total int64
+
+	baseQuery := conn(ctx, r.db).Model(&entities.Notification{}).Where("user_id = ?", userID)
+
+	if unreadOnly {
+		baseQuery = baseQuery.Where("read_at IS NULL")
+	}
+
+	if err := baseQuery.
+		Count(&total).
+		Order("created_at desc").
+		Limit(limit).
+		Offset(offset).
+		Find(&notifications).Error; err != nil {
+		return nil, 0, TranslateError(err)
+	}
+
+	return notifications, int(total), nil
+}
+
+func (r *gormNotificationRepository) CountUnread(ctx context.Context, userID string) (int, *core.Exception) {
+	var total int64
+
+	if err := conn(ctx, r.db).Model(&entities.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&total).Error; err != nil {
+		return 0, TranslateError(err)
+	}
+
+	return int(total), nil
+}
+
+func (r *gormNotificationRepository) MarkRead(ctx context.Context, userID, id string) *core.Exception {
+	var notification entities.Notification
+
+	if err := conn(ctx, r.db).First(&notification, "id = ? AND user_id = ?", id, userID).Error; err != nil {
+		return TranslateError(err)
+	}
+
+	if notification.ReadAt != nil {
+		return nil
+	}
+
+	if err := conn(ctx, r.db).Model(&notification).Update("read_at", r.clock.Now()).Error; err != nil {
+		return TranslateError(err)
+	}
+
+	return nil
+}
+
+func (r *gormNotificationRepository) MarkAllRead(ctx context.Context, userID string) *core.Exception {
+	err := conn(ctx, r.db).Model(&entities.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", r.clock.Now()).Error
+	if err != nil {
+		return TranslateError(err)
+	}
+
+	return nil
+}
+
+func (r *gormNotificationRepository) GetPreference(ctx context.Context, userID, event string) (entities.NotificationPreference, *core.Exception) {
+	var preference entities.NotificationPreference
+
+	if err := conn(ctx, r.db).First(&preference, "user_id = ? AND event = ?", userID, event).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.NotificationPreference{UserID: userID, Event: event}, nil
+		}
+		return entities.NotificationPreference{}, TranslateError(err)
+	}
+
+	return preference, nil
+}
+
+func (r *gormNotificationRepository) GetPreferences(ctx context.Context, userID string) ([]entities.NotificationPreference, *core.Exception) {
+	var preferences []entities.NotificationPreference
+
+	if err := conn(ctx, r.db).Where("user_id = ?", userID).Find(&preferences).Error; err != nil {
+		return nil, TranslateError(err)
+	}
+
+	return preferences, nil
+}
+
+func (r *gormNotificationRepository) SavePreference(ctx context.Context, preference entities.NotificationPreference) (entities.NotificationPreference, *core.Exception) {
+	if err := conn(ctx, r.db).Save(&preference).Error; err != nil {
+		return entities.NotificationPreference{}, TranslateError(err)
+	}
+
+	return preference, nil
+}
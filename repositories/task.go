@@ -2,7 +2,7 @@ package repositories
 
 import (
 	"context"
-	"errors"
+	"time"
 
 	"github.com/mirusky-dev/challenge-18/core"
 	"github.com/mirusky-dev/challenge-18/models/entities"
@@ -13,96 +13,79 @@ import (
 
 type ITaskRepository interface {
 	core.IBaseRepository[string, entities.Task, entities.Task, entities.Task]
-}
 
-type gormTaskRepository struct {
-	db *gorm.DB
+	// Find returns the tasks matching the filter, it's meant for background jobs so it doesn't apply the context visibility
+	Find(ctx context.Context, filter TaskFilter) ([]entities.Task, *core.Exception)
 }
 
-func NewTaskRepository(db *gorm.DB) ITaskRepository {
-	return &gormTaskRepository{db}
-}
+// TaskFilter narrows Find, the zero value of a field means it isn't filtered.
+//
+// The ranges are half-open, from is included and to isn't.
+type TaskFilter struct {
+	UserIDs []string
 
-func (r *gormTaskRepository) Create(ctx context.Context, entity entities.Task) (entities.Task, *core.Exception) {
-	err := r.db.Create(&entity).Error
-	if err != nil {
-		return entities.Task{}, core.Unexpected(core.WithError(err))
-	}
+	CreatedFrom   time.Time
+	CreatedTo     time.Time
+	PerformedFrom time.Time
+	PerformedTo   time.Time
 
-	return entity, nil
+	// OnlyOpen keeps the tasks that weren't performed yet
+	OnlyOpen bool
 }
 
-func (r *gormTaskRepository) GetByID(ctx context.Context, id string) (entities.Task, *core.Exception) {
-	appCtx, _ := core.FromContext(ctx)
-
-	baseQuery := r.db.Model(&entities.Task{})
-
-	if !slices.Contains(appCtx.Roles(), "manager") {
-		baseQuery = baseQuery.Where("user_id = ?", appCtx.UserID())
-	}
-
-	var task entities.Task
-	if err := baseQuery.First(&task, "id = ?", id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return entities.Task{}, core.NotFound()
-		}
-		return entities.Task{}, core.Unexpected(core.WithError(err))
-	}
+type gormTaskRepository struct {
+	*GormRepository[entities.Task]
+}
 
-	return task, nil
+func NewTaskRepository(db *gorm.DB) ITaskRepository {
+	return &gormTaskRepository{NewGormRepository[entities.Task](db, visibleTasks)}
 }
 
-func (r *gormTaskRepository) GetAll(ctx context.Context, limit, offset int) ([]entities.Task, int, *core.Exception) {
+// visibleTasks keeps the tasks of the user in ctx, a manager sees all of them
+func visibleTasks(ctx context.Context, db *gorm.DB) *gorm.DB {
 	appCtx, _ := core.FromContext(ctx)
 
-	var tasks []entities.Task
-	var total int64
-	baseQuery := r.db.Model(&entities.Task{})
-
-	if !slices.Contains(appCtx.Roles(), "manager") {
-		baseQuery = baseQuery.Where("user_id = ?", appCtx.UserID())
+	if slices.Contains(appCtx.Roles(), "manager") {
+		return db
 	}
 
-	baseQuery.Count(&total).Limit(limit).Offset(offset).Find(&tasks)
-
-	return tasks, int(total), nil
+	return db.Where("user_id = ?", appCtx.UserID())
 }
 
-func (r *gormTaskRepository) Update(ctx context.Context, id string, changes entities.Task) (entities.Task, *core.Exception) {
-	appCtx, _ := core.FromContext(ctx)
+func (r *gormTaskRepository) Find(ctx context.Context, filter TaskFilter) ([]entities.Task, *core.Exception) {
+	query := conn(ctx, r.db).Model(&entities.Task{})
 
-	baseQuery := r.db.Model(&entities.Task{})
-	if !slices.Contains(appCtx.Roles(), "manager") {
-		baseQuery = baseQuery.Where("user_id = ?", appCtx.UserID())
+	if filter.UserIDs != nil {
+		if len(filter.UserIDs) == 0 {
+			return []entities.Task{}, nil
+		}
+		query = query.Where("user_id IN ?", filter.UserIDs)
 	}
 
-	baseQuery = baseQuery.Where("id = ?", id)
-	if changes.Summary != "" {
-		baseQuery = baseQuery.Update("summary", changes.Summary)
+	if !filter.CreatedFrom.IsZero() {
+		query = query.Where("created_at >= ?", filter.CreatedFrom)
 	}
 
-	// if changes.UserID != "" {
-	// 	baseQuery = baseQuery.Update("user_id", changes.UserID)
-	// }
-
-	if changes.PerformedAt != nil {
-		baseQuery = baseQuery.Update("performed_at", changes.PerformedAt)
+	if !filter.CreatedTo.IsZero() {
+		query = query.Where("created_at < ?", filter.CreatedTo)
 	}
 
-	var task entities.Task
-	if err := baseQuery.Find(&task).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return entities.Task{}, core.NotFound()
-		}
-		return entities.Task{}, core.Unexpected(core.WithError(err))
+	if !filter.PerformedFrom.IsZero() {
+		query = query.Where("performed_at >= ?", filter.PerformedFrom)
 	}
 
-	return task, nil
-}
+	if !filter.PerformedTo.IsZero() {
+		query = query.Where("performed_at < ?", filter.PerformedTo)
+	}
 
-func (r *gormTaskRepository) Delete(ctx context.Context, id string) *core.Exception {
+	if filter.OnlyOpen {
+		query = query.Where("performed_at IS NULL")
+	}
 
-	r.db.Delete(&entities.Task{}, "id = ?", id)
+	var tasks []entities.Task
+	if err := query.Order("created_at").Find(&tasks).Error; err != nil {
+		return nil, TranslateError(err)
+	}
 
-	return nil
+	return tasks, nil
 }
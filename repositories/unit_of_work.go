@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/mirusky-dev/challenge-18/core"
+)
+
+type txKey struct{}
+
+type gormUnitOfWork struct {
+	db *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) core.UnitOfWork {
+	return &gormUnitOfWork{db}
+}
+
+func (u *gormUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) *core.Exception) *core.Exception {
+	var exception *core.Exception
+
+	err := conn(ctx, u.db).Transaction(func(tx *gorm.DB) error {
+		exception = fn(context.WithValue(ctx, txKey{}, tx))
+		if exception != nil {
+			return exception
+		}
+
+		return nil
+	})
+
+	if exception != nil {
+		return exception
+	}
+
+	if err != nil {
+		return core.Unexpected(core.WithError(err))
+	}
+
+	return nil
+}
+
+// conn returns the transaction of the unit of work running in ctx, or db outside of one. Every repository query
+// starts from it, so the repositories take part in the unit of work without knowing about it.
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+
+	return db.WithContext(ctx)
+}
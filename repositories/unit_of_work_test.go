@@ -0,0 +1,71 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/models/entities"
+	"github.com/mirusky-dev/challenge-18/repositories"
+	"github.com/mirusky-dev/challenge-18/testkit"
+	"github.com/mirusky-dev/challenge-18/testkit/integration"
+)
+
+func TestUnitOfWork(t *testing.T) {
+	db := integration.Open(t, testkit.NewIDs())
+	uow := repositories.NewUnitOfWork(db)
+	users := repositories.NewUserRepository(db)
+	tasks := repositories.NewTaskRepository(db)
+
+	ctx := context.Background()
+	count := func() (n int64) {
+		db.Model(&entities.User{}).Count(&n)
+		return n
+	}
+
+	exception := uow.Do(ctx, func(ctx context.Context) *core.Exception {
+		user, err := users.Create(ctx, entities.User{Username: "tech", Role: "tech"})
+		if err != nil {
+			return err
+		}
+
+		_, err = tasks.Create(ctx, entities.Task{Summary: "Fix the printer", UserID: user.ID})
+		return err
+	})
+	assert.Nil(t, exception)
+	assert.EqualValues(t, 1, count(), "committed")
+
+	exception = uow.Do(ctx, func(ctx context.Context) *core.Exception {
+		if _, err := users.Create(ctx, entities.User{Username: "manager", Role: "manager"}); err != nil {
+			return err
+		}
+
+		return core.Forbidden()
+	})
+	assert.Equal(t, core.Forbidden().Status, exception.Status)
+	assert.EqualValues(t, 1, count(), "rolled back by the exception")
+
+	assert.Panics(t, func() {
+		_ = uow.Do(ctx, func(ctx context.Context) *core.Exception {
+			_, _ = users.Create(ctx, entities.User{Username: "manager", Role: "manager"})
+			panic("boom")
+		})
+	})
+	assert.EqualValues(t, 1, count(), "rolled back by the panic")
+
+	exception = uow.Do(ctx, func(ctx context.Context) *core.Exception {
+		_, _ = users.Create(ctx, entities.User{Username: "outer", Role: "tech"})
+
+		// The nested unit of work is a savepoint, its rollback keeps the outer changes
+		_ = uow.Do(ctx, func(ctx context.Context) *core.Exception {
+			_, _ = users.Create(ctx, entities.User{Username: "inner", Role: "tech"})
+			return core.BadRequest()
+		})
+
+		return nil
+	})
+	assert.Nil(t, exception)
+	assert.EqualValues(t, 2, count(), "the outer user is committed, the inner one rolled back")
+}
@@ -2,7 +2,6 @@ package repositories
 
 import (
 	"context"
-	"errors"
 
 	"github.com/mirusky-dev/challenge-18/core"
 	"github.com/mirusky-dev/challenge-18/models/entities"
@@ -15,105 +14,80 @@ type IUserRepository interface {
 
 	FindByUsernameOrEmail(ctx context.Context, username, email string) (entities.User, *core.Exception)
 	ChangePassword(ctx context.Context, userID, hashedPassword, signature string) *core.Exception
+
+	// FindByManagerID returns the techs of the manager
+	FindByManagerID(ctx context.Context, managerID string) ([]entities.User, *core.Exception)
+	// FindByDigestFrequency returns the users that chose to receive a digest with the given frequency
+	FindByDigestFrequency(ctx context.Context, frequency string) ([]entities.User, *core.Exception)
 }
 
 type gormUserRepository struct {
-	db *gorm.DB
+	*GormRepository[entities.User]
 }
 
 func NewUserRepository(db *gorm.DB) IUserRepository {
-	return &gormUserRepository{db}
+	return &gormUserRepository{NewGormRepository[entities.User](db, withManager)}
 }
 
-func (r *gormUserRepository) Create(ctx context.Context, entity entities.User) (entities.User, *core.Exception) {
-	err := r.db.Create(&entity).Error
-	if err != nil {
-		return entities.User{}, core.Unexpected(core.WithError(err))
-	}
-
-	return entity, nil
+// withManager preloads the manager of the users
+func withManager(ctx context.Context, db *gorm.DB) *gorm.DB {
+	return db.Preload("Manager")
 }
 
 func (r *gormUserRepository) FindByUsernameOrEmail(ctx context.Context, username, email string) (entities.User, *core.Exception) {
 
 	var user entities.User
-	if err := r.db.
+	if err := r.Query(ctx).
 		Or(entities.User{Username: username}).
 		Or(entities.User{Email: email}).
-		Preload("Manager").
 		First(&user).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return entities.User{}, core.NotFound()
-		}
-		return entities.User{}, core.Unexpected(core.WithError(err))
+		return entities.User{}, TranslateError(err)
 	}
 
 	return user, nil
 }
 
 func (r *gormUserRepository) ChangePassword(ctx context.Context, id, hashedPassword, signature string) *core.Exception {
+	result := r.Query(ctx).Where("id = ?", id).Updates(entities.User{Password: hashedPassword, Signature: signature})
+	if result.Error != nil {
+		return TranslateError(result.Error)
+	}
 
-	var user entities.User
-	r.db.Where(&entities.User{ID: id}).Find(&user)
-
-	if user.ID != id {
+	// The signature is new, so an existing user always has a changed row
+	if result.RowsAffected == 0 {
 		return core.NotFound()
 	}
 
-	user.Password = hashedPassword
-	user.Signature = signature
-
-	r.db.Save(&user)
-
 	return nil
 }
 
+// GetByID returns the user with its manager and tasks
 func (r *gormUserRepository) GetByID(ctx context.Context, id string) (entities.User, *core.Exception) {
 	var user entities.User
 
-	if err := r.db.Preload("Manager").Preload("Tasks").First(&user, "id = ?", id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return entities.User{}, core.NotFound()
-		}
-		return entities.User{}, core.Unexpected(core.WithError(err))
+	if err := r.Query(ctx).Preload("Tasks").First(&user, "id = ?", id).Error; err != nil {
+		return entities.User{}, TranslateError(err)
 	}
 
 	return user, nil
 }
 
-func (r *gormUserRepository) GetAll(ctx context.Context, limit, offset int) ([]entities.User, int, *core.Exception) {
-
+func (r *gormUserRepository) FindByManagerID(ctx context.Context, managerID string) ([]entities.User, *core.Exception) {
 	var users []entities.User
-	var total int64
-	r.db.Model(&entities.User{}).Preload("Manager").Count(&total).Limit(limit).Offset(offset).Find(&users)
-
-	return users, int(total), nil
-}
 
-func (r *gormUserRepository) Update(ctx context.Context, id string, changes entities.User) (entities.User, *core.Exception) {
-	var user entities.User
-
-	query := r.db.Model(&entities.User{}).Where("id = ?", id).Preload("Manager")
-	if changes.Username != "" {
-		query = query.Update("username", changes.Username)
-	}
-
-	if changes.Email != "" {
-		query = query.Update("email", changes.Email)
-	}
-
-	if changes.IsEmailVerified != nil {
-		query = query.Update("is_email_verified", *changes.IsEmailVerified)
+	if err := conn(ctx, r.db).Where("manager_id = ?", managerID).Find(&users).Error; err != nil {
+		return nil, TranslateError(err)
 	}
 
-	query.Find(&user)
-
-	return user, nil
+	return users, nil
 }
 
-func (r *gormUserRepository) Delete(ctx context.Context, id string) *core.Exception {
+func (r *gormUserRepository) FindByDigestFrequency(ctx context.Context, frequency string) ([]entities.User, *core.Exception) {
+	var users []entities.User
 
-	r.db.Delete(&entities.User{}, "id = ?", id)
+	if err := conn(ctx, r.db).Where("digest_frequency = ?", frequency).Find(&users).Error; err != nil {
+		return nil, TranslateError(err)
+	}
 
-	return nil
+	return users, nil
 }
@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/models/entities"
+
+	"gorm.io/gorm"
+)
+
+type IWebhookRepository interface {
+	core.IBaseRepository[string, entities.Webhook, entities.Webhook, entities.Webhook]
+
+	// FindByEvent returns the active webhooks subscribed to the given event type
+	FindByEvent(ctx context.Context, event string) ([]entities.Webhook, *core.Exception)
+
+	CreateDelivery(ctx context.Context, delivery entities.WebhookDelivery) (entities.WebhookDelivery, *core.Exception)
+	GetDeliveryByID(ctx context.Context, id string) (entities.WebhookDelivery, *core.Exception)
+	GetDeliveries(ctx context.Context, webhookID string, limit, offset int) ([]entities.WebhookDelivery, int, *core.Exception)
+	SaveDelivery(ctx context.Context, delivery entities.WebhookDelivery) *core.Exception
+}
+
+type gormWebhookRepository struct {
+	*GormRepository[entities.Webhook]
+}
+
+func NewWebhookRepository(db *gorm.DB) IWebhookRepository {
+	return &gormWebhookRepository{NewGormRepository[entities.Webhook](db)}
+}
+
+func (r *gormWebhookRepository) FindByEvent(ctx context.Context, event string) ([]entities.Webhook, *core.Exception) {
+	var webhooks []entities.Webhook
+
+	if err := conn(ctx, r.db).Where("active = ?", true).Find(&webhooks).Error; err != nil {
+		return nil, TranslateError(err)
+	}
+
+	// Events are stored comma separated, so the filter is done here to not depend on database specific functions
+	var subscribed []entities.Webhook
+	for _, webhook := range webhooks {
+		for _, e := range strings.Split(webhook.Events, ",") {
+			if e = strings.TrimSpace(e); e == "*" || strings.EqualFold(e, event) {
+				subscribed = append(subscribed, webhook)
+				break
+			}
+		}
+	}
+
+	return subscribed, nil
+}
+
+func (r *gormWebhookRepository) CreateDelivery(ctx context.Context, delivery entities.WebhookDelivery) (entities.WebhookDelivery, *core.Exception) {
+	err := conn(ctx, r.db).Create(&delivery).Error
+	if err != nil {
+		return entities.WebhookDelivery{}, TranslateError(err)
+	}
+
+	return delivery, nil
+}
+
+func (r *gormWebhookRepository) GetDeliveryByID(ctx context.Context, id string) (entities.WebhookDelivery, *core.Exception) {
+	var delivery entities.WebhookDelivery
+
+	if err := conn(ctx, r.db).First(&delivery, "id = ?", id).Error; err != nil {
+		return entities.WebhookDelivery{}, TranslateError(err)
+	}
+
+	return delivery, nil
+}
+
+func (r *gormWebhookRepository) GetDeliveries(ctx context.Context, webhookID string, limit, offset int) ([]entities.WebhookDelivery, int, *core.Exception) {
+	var deliveries []entities.WebhookDelivery
+	var total int64
+
+	if err := conn(ctx, r.db).Model(&entities.WebhookDelivery{}).
+		Where("webhook_id = ?", webhookID).
+		Count(&total).
+		Order("created_at desc").
+		Limit(limit).
+		Offset(offset).
+		Find(&deliveries).Error; err != nil {
+		return nil, 0, TranslateError(err)
+	}
+
+	return deliveries, int(total), nil
+}
+
+func (r *gormWebhookRepository) SaveDelivery(ctx context.Context, delivery entities.WebhookDelivery) *core.Exception {
+	if err := conn(ctx, r.db).Save(&delivery).Error; err != nil {
+		return TranslateError(err)
+	}
+
+	return nil
+}
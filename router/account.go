@@ -22,6 +22,47 @@ func (ctrl Controller) changePassword(c *fiber.Ctx) error {
 	return c.SendStatus(200)
 }
 
+func (ctrl Controller) changeLocale(c *fiber.Ctx) error {
+	var dto dtos.ChangeLocale
+
+	if err := c.BodyParser(&dto); err != nil {
+		return core.BadRequest(core.WithError(err))
+	}
+
+	exception := ctrl.accountService.ChangeLocale(c.UserContext(), dto)
+	if exception != nil {
+		return exception
+	}
+
+	appCtx, ok := core.FromContext(c.UserContext())
+	if !ok {
+		return core.MissingContext()
+	}
+
+	// The locale of the user is read from the token, the new one carries it right away
+	token, refreshToken, expiresAt, refreshExpiresAt, exception := ctrl.tokenService.Issue(c.UserContext(), appCtx.UserID())
+	if exception != nil {
+		return exception
+	}
+
+	return sendToken(c, token, refreshToken, expiresAt, refreshExpiresAt)
+}
+
+func (ctrl Controller) changeDigestFrequency(c *fiber.Ctx) error {
+	var dto dtos.ChangeDigestFrequency
+
+	if err := c.BodyParser(&dto); err != nil {
+		return core.BadRequest(core.WithError(err))
+	}
+
+	exception := ctrl.accountService.ChangeDigestFrequency(c.UserContext(), dto)
+	if exception != nil {
+		return exception
+	}
+
+	return c.SendStatus(200)
+}
+
 func (ctrl Controller) sendEmailVerificationLink(c *fiber.Ctx) error {
 	exception := ctrl.accountService.SendVerificationEmail(c.UserContext(), c.BaseURL())
 
@@ -2,6 +2,7 @@ package router
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v4"
@@ -86,28 +87,14 @@ func (ctrl Controller) login(c *fiber.Ctx) error {
 		return exception
 	}
 
-	c.Cookie(&fiber.Cookie{
-		Name:     refreshTokenKey,
-		Value:    refreshToken,
-		Expires:  refreshExpiresAt,
-		HTTPOnly: true,
-		SameSite: fiber.CookieSameSiteLaxMode,
-		Secure:   true,
-	})
-
-	return c.JSON(fiber.Map{
-		"token":            token,
-		"expiresAt":        expiresAt.Unix(),
-		"refreshToken":     refreshToken,
-		"refreshExpiresAt": refreshExpiresAt,
-	})
+	return sendToken(c, token, refreshToken, expiresAt, refreshExpiresAt)
 }
 
 func (ctrl Controller) refreshToken(c *fiber.Ctx) error {
 	oldRefreshToken := c.Query("refresh_token", c.Cookies(refreshTokenKey))
 
 	if oldRefreshToken == "" {
-		return core.BadRequest(core.WithMessage("Missing refresh token"))
+		return core.BadRequest(core.WithMessageKey(core.MessageMissingRefreshToken, nil))
 	}
 
 	var dto = dtos.RefreshToken{
@@ -120,6 +107,11 @@ func (ctrl Controller) refreshToken(c *fiber.Ctx) error {
 		return exception
 	}
 
+	return sendToken(c, token, refreshToken, expiresAt, refreshExpiresAt)
+}
+
+// sendToken returns the tokens, the refresh token is also set as a cookie
+func sendToken(c *fiber.Ctx, token, refreshToken string, expiresAt, refreshExpiresAt time.Time) error {
 	c.Cookie(&fiber.Cookie{
 		Name:     refreshTokenKey,
 		Value:    refreshToken,
@@ -129,11 +121,11 @@ func (ctrl Controller) refreshToken(c *fiber.Ctx) error {
 		Secure:   true,
 	})
 
-	return c.JSON(fiber.Map{
-		"token":            token,
-		"expiresAt":        expiresAt.Unix(),
-		"refreshToken":     refreshToken,
-		"refreshExpiresAt": refreshExpiresAt,
+	return c.JSON(dtos.Token{
+		Token:            token,
+		ExpiresAt:        expiresAt.Unix(),
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
 	})
 }
 
@@ -1,12 +1,40 @@
 package router
 
 import (
+	"database/sql"
 	"errors"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/core/env"
+	"github.com/mirusky-dev/challenge-18/core/mailer"
 )
 
+// debugRoutes drops the debug routes when they're disabled, outside DEV they're only for admins
+func debugRoutes(routes []Route, config env.Config) []Route {
+	var filtered []Route
+	for _, route := range routes {
+		if route.Debug {
+			if !config.EnableDebug {
+				continue
+			}
+
+			if !config.IsDev() {
+				route.Public = false
+				route.Roles = []string{"admin"}
+			}
+		}
+
+		filtered = append(filtered, route)
+	}
+
+	return filtered
+}
+
 func (ctrl Controller) empty(c *fiber.Ctx) error {
 	return nil
 }
@@ -46,3 +74,140 @@ func (ctrl Controller) friendlyError(c *fiber.Ctx) error {
 
 	return core.UserFriendlyException(opts...)
 }
+
+// mailPreview renders a mail template with sample data
+//
+//	?locale=pt-BR&format=html|text|json
+func (ctrl Controller) mailPreview(c *fiber.Ctx) error {
+	content, err := ctrl.templates.Preview(c.Params("name"), c.Query("locale", mailer.DefaultLocale))
+	if err != nil {
+		if errors.Is(err, mailer.ErrTemplateNotFound) {
+			return core.NotFound(core.WithMessage(err.Error()))
+		}
+		return core.Unexpected(core.WithError(err))
+	}
+
+	switch c.Query("format", "html") {
+	case "text":
+		return c.SendString(content.PlainText)
+	case "json":
+		return c.JSON(content)
+	default:
+		c.Type("html", "utf-8")
+		return c.SendString(content.HTML)
+	}
+}
+
+// effectiveConfig returns the effective config, the secrets are redacted
+func (ctrl Controller) effectiveConfig(c *fiber.Ctx) error {
+	return c.JSON(ctrl.config.Redacted())
+}
+
+type routeInfo struct {
+	Method string   `json:"method"`
+	Path   string   `json:"path"`
+	Public bool     `json:"public"`
+	Roles  []string `json:"roles"`
+}
+
+// routeTable returns the registered routes with who can call them
+func (ctrl Controller) routeTable(c *fiber.Ctx) error {
+	routes := debugRoutes(ctrl.routes(), ctrl.config)
+
+	table := make([]routeInfo, 0, len(routes))
+	for _, route := range routes {
+		table = append(table, routeInfo{
+			Method: route.Method,
+			Path:   route.Path,
+			Public: route.Public,
+			Roles:  route.Roles,
+		})
+	}
+
+	return c.JSON(table)
+}
+
+type runtimeInfo struct {
+	GoVersion    string `json:"goVersion"`
+	NumCPU       int    `json:"numCpu"`
+	GOMAXPROCS   int    `json:"gomaxprocs"`
+	NumGoroutine int    `json:"numGoroutine"`
+	HeapAlloc    uint64 `json:"heapAlloc"`   // bytes
+	HeapObjects  uint64 `json:"heapObjects"` // count
+	Sys          uint64 `json:"sys"`         // bytes
+	NumGC        uint32 `json:"numGc"`
+	PauseTotal   string `json:"pauseTotal"`
+}
+
+// runtimeStats returns the goroutines and memory of the process, see pprof for the details
+func (ctrl Controller) runtimeStats(c *fiber.Ctx) error {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	return c.JSON(runtimeInfo{
+		GoVersion:    runtime.Version(),
+		NumCPU:       runtime.NumCPU(),
+		GOMAXPROCS:   runtime.GOMAXPROCS(0),
+		NumGoroutine: runtime.NumGoroutine(),
+		HeapAlloc:    mem.HeapAlloc,
+		HeapObjects:  mem.HeapObjects,
+		Sys:          mem.Sys,
+		NumGC:        mem.NumGC,
+		PauseTotal:   time.Duration(mem.PauseTotalNs).String(),
+	})
+}
+
+type dbPoolStats struct {
+	MaxOpenConnections int    `json:"maxOpenConnections"`
+	OpenConnections    int    `json:"openConnections"`
+	InUse              int    `json:"inUse"`
+	Idle               int    `json:"idle"`
+	WaitCount          int64  `json:"waitCount"`
+	WaitDuration       string `json:"waitDuration"`
+	MaxIdleClosed      int64  `json:"maxIdleClosed"`
+	MaxIdleTimeClosed  int64  `json:"maxIdleTimeClosed"`
+	MaxLifetimeClosed  int64  `json:"maxLifetimeClosed"`
+}
+
+// dbStats returns the connection pool stats of the database
+func (ctrl Controller) dbStats(c *fiber.Ctx) error {
+	var stats sql.DBStats
+	if ctrl.db != nil {
+		stats = ctrl.db.Stats()
+	}
+
+	return c.JSON(dbPoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       stats.WaitDuration.String(),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	})
+}
+
+// pprof serves a runtime profile, e.g: goroutine?debug=2 dumps the stacks and profile?seconds=30 samples the CPU
+//
+//	go tool pprof -http=: http://localhost:4000/api/debug/pprof/heap
+func (ctrl Controller) pprof(c *fiber.Ctx) error {
+	var handler http.Handler
+
+	switch name := c.Params("profile"); name {
+	case "cmdline":
+		handler = http.HandlerFunc(pprof.Cmdline)
+	case "profile":
+		handler = http.HandlerFunc(pprof.Profile)
+	case "symbol":
+		handler = http.HandlerFunc(pprof.Symbol)
+	case "trace":
+		handler = http.HandlerFunc(pprof.Trace)
+	default:
+		// Answers 404 for an unknown profile
+		handler = pprof.Handler(name)
+	}
+
+	return httpHandler(handler)(c)
+}
@@ -0,0 +1,37 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mirusky-dev/challenge-18/core/env"
+)
+
+func TestDebugRoutes(t *testing.T) {
+	routes := Controller{}.routes()
+
+	find := func(routes []Route, path string) (Route, bool) {
+		for _, route := range routes {
+			if route.Path == path {
+				return route, true
+			}
+		}
+		return Route{}, false
+	}
+
+	_, ok := find(debugRoutes(routes, env.Config{Environment: env.PRODUCTION}), "/api/debug/ping")
+	assert.False(t, ok, "disabled by default outside DEV")
+
+	ping, ok := find(debugRoutes(routes, env.Config{Environment: env.DEV, EnableDebug: true}), "/api/debug/ping")
+	assert.True(t, ok)
+	assert.True(t, ping.Public)
+
+	config, ok := find(debugRoutes(routes, env.Config{Environment: env.STAGING, EnableDebug: true}), "/api/debug/config")
+	assert.True(t, ok)
+	assert.False(t, config.Public, "outside DEV it needs a token")
+	assert.Equal(t, []string{"admin"}, config.Roles)
+
+	_, ok = find(debugRoutes(routes, env.Config{Environment: env.PRODUCTION}), "/api/v1/tasks")
+	assert.True(t, ok, "only the debug routes are filtered")
+}
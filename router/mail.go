@@ -0,0 +1,36 @@
+package router
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mirusky-dev/challenge-18/core"
+)
+
+func (ctrl Controller) getDeadLetterMails(c *fiber.Ctx) error {
+	var input core.PaginationParams
+
+	if err := c.QueryParser(&input); err != nil {
+		return core.BadRequest(core.WithError(err))
+	}
+
+	input.Default()
+
+	items, total, exception := ctrl.mailService.DeadLetters(c.UserContext(), *input.Limit, *input.Offset)
+	if exception != nil {
+		return exception
+	}
+
+	response := core.Page(items, total, *input.Limit, *input.Offset)
+	return c.JSON(response)
+}
+
+func (ctrl Controller) retryDeadLetterMail(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	exception := ctrl.mailService.RetryDeadLetter(c.UserContext(), id)
+	if exception != nil {
+		return exception
+	}
+
+	return c.SendStatus(200)
+}
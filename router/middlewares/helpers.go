@@ -76,3 +76,15 @@ func FromCookie(name string) func(c *fiber.Ctx) (string, error) {
 		return value, nil
 	}
 }
+
+// handleError writes the error of the chain with the app error handler, so the middlewares that run after the
+// chain see the final status. It's what fiber does with the errors that reach the app.
+func handleError(c *fiber.Ctx, err error) {
+	if err == nil {
+		return
+	}
+
+	if err := c.App().ErrorHandler(c, err); err != nil {
+		_ = c.SendStatus(fiber.StatusInternalServerError)
+	}
+}
@@ -15,9 +15,22 @@ func JWT(config env.Config) fiber.Handler {
 		Claims:     &core.Claims{},
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			if err.Error() == "Missing or malformed JWT" {
-				return core.BadRequest(core.WithMessage(err.Error()))
+				return core.BadRequest(core.WithMessageKey(core.MessageMissingToken, nil))
 			}
-			return core.Unauthorized(core.WithMessage("Invalid or expired JWT"))
+			return core.Unauthorized(core.WithMessageKey(core.MessageInvalidToken, nil))
 		},
 	})
 }
+
+// QueryToken moves the access_token query parameter to the Authorization header, EventSource can't send headers.
+//
+// Only mount it on the routes that need it, a token in the URL ends up in the access logs and the Referer headers.
+func QueryToken() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if token := c.Query("access_token"); token != "" && c.Get(fiber.HeaderAuthorization) == "" {
+			c.Request().Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+		}
+
+		return c.Next()
+	}
+}
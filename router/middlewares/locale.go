@@ -0,0 +1,20 @@
+package middlewares
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/mirusky-dev/challenge-18/core/i18n"
+)
+
+// Locale middleware stores the locale asked by the Accept-Language header,
+// without it the User middleware uses the user's locale
+func Locale() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+
+		if locale := i18n.Match(c.Get(fiber.HeaderAcceptLanguage)); locale != "" {
+			c.SetUserContext(i18n.NewContext(c.UserContext(), locale))
+			c.Set(fiber.HeaderContentLanguage, locale)
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mirusky-dev/challenge-18/core/metrics"
+)
+
+// Metrics observes the duration of each request by route and status.
+//
+// The route is the registered path, e.g: /api/v1/tasks/:id, so the IDs don't explode the series.
+func Metrics() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		handleError(c, c.Next())
+
+		metrics.HTTPRequestDuration.
+			WithLabelValues(c.Method(), c.Route().Path, strconv.Itoa(c.Response().StatusCode())).
+			Observe(time.Since(start).Seconds())
+
+		return nil
+	}
+}
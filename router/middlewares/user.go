@@ -4,6 +4,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/core/i18n"
 	"github.com/mirusky-dev/challenge-18/services"
 )
 
@@ -29,7 +30,14 @@ func User(tokenService services.ITokenService) fiber.Handler {
 			SetUserID(claims.Subject).
 			SetRoles([]string{claims.Role})
 
-		c.SetUserContext(core.NewContext(c.Context(), userCtx))
+		ctx := core.NewContext(c.UserContext(), userCtx)
+
+		// Accept-Language wins over the user's locale
+		if _, ok := i18n.FromContext(ctx); !ok && claims.Locale != "" {
+			ctx = i18n.NewContext(ctx, claims.Locale)
+		}
+
+		c.SetUserContext(ctx)
 
 		return c.Next()
 	}
@@ -0,0 +1,112 @@
+package router
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/models/dtos"
+)
+
+// notificationPage is a page of notifications with the unread count, so the client can show a badge in one request
+type notificationPage struct {
+	core.PagedResponse[dtos.Notification]
+	Unread int `json:"unread"`
+}
+
+func (ctrl Controller) getAllNotifications(c *fiber.Ctx) error {
+	var input core.PaginationParams
+
+	if err := c.QueryParser(&input); err != nil {
+		return core.BadRequest(core.WithError(err))
+	}
+
+	input.Default()
+
+	notifications, total, exception := ctrl.notificationService.GetAll(c.UserContext(), c.Query("unread") == "true", *input.Limit, *input.Offset)
+	if exception != nil {
+		return exception
+	}
+
+	unread, exception := ctrl.notificationService.UnreadCount(c.UserContext())
+	if exception != nil {
+		return exception
+	}
+
+	items := []dtos.Notification{}
+	for _, notification := range *notifications {
+		var item dtos.Notification
+		item.FromEntity(notification)
+		items = append(items, item)
+	}
+
+	return c.JSON(notificationPage{
+		PagedResponse: core.Page(items, total, *input.Limit, *input.Offset),
+		Unread:        unread,
+	})
+}
+
+func (ctrl Controller) getUnreadNotificationsCount(c *fiber.Ctx) error {
+	unread, exception := ctrl.notificationService.UnreadCount(c.UserContext())
+	if exception != nil {
+		return exception
+	}
+
+	return c.JSON(fiber.Map{
+		"unread": unread,
+	})
+}
+
+func (ctrl Controller) markNotificationRead(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	exception := ctrl.notificationService.MarkRead(c.UserContext(), id)
+	if exception != nil {
+		return exception
+	}
+
+	return c.SendStatus(200)
+}
+
+func (ctrl Controller) markAllNotificationsRead(c *fiber.Ctx) error {
+	exception := ctrl.notificationService.MarkAllRead(c.UserContext())
+	if exception != nil {
+		return exception
+	}
+
+	return c.SendStatus(200)
+}
+
+func (ctrl Controller) getNotificationPreferences(c *fiber.Ctx) error {
+	preferences, exception := ctrl.notificationService.Preferences(c.UserContext())
+	if exception != nil {
+		return exception
+	}
+
+	items := []dtos.NotificationPreference{}
+	for _, preference := range *preferences {
+		var item dtos.NotificationPreference
+		item.FromEntity(preference)
+		items = append(items, item)
+	}
+
+	return c.JSON(items)
+}
+
+func (ctrl Controller) updateNotificationPreference(c *fiber.Ctx) error {
+	var input dtos.UpdateNotificationPreference
+
+	if err := c.BodyParser(&input); err != nil {
+		return core.BadRequest(core.WithError(err))
+	}
+
+	input.Event = c.Params("event")
+
+	preference, exception := ctrl.notificationService.UpdatePreference(c.UserContext(), input)
+	if exception != nil {
+		return exception
+	}
+
+	var result dtos.NotificationPreference
+	result.FromEntity(*preference)
+
+	return c.JSON(result)
+}
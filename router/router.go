@@ -1,19 +1,28 @@
 package router
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
-	"github.com/gofiber/storage/redis"
-	"github.com/hibiken/asynq"
-	"gorm.io/driver/mysql"
-	"gorm.io/gorm"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
 
+	"github.com/mirusky-dev/challenge-18/container"
 	"github.com/mirusky-dev/challenge-18/core"
 	"github.com/mirusky-dev/challenge-18/core/env"
+	"github.com/mirusky-dev/challenge-18/core/health"
+	"github.com/mirusky-dev/challenge-18/core/lifecycle"
 	"github.com/mirusky-dev/challenge-18/core/mailer"
-	"github.com/mirusky-dev/challenge-18/repositories"
+	"github.com/mirusky-dev/challenge-18/core/metrics"
+	"github.com/mirusky-dev/challenge-18/core/openapi"
+	"github.com/mirusky-dev/challenge-18/core/stream"
 	"github.com/mirusky-dev/challenge-18/router/middlewares"
 	"github.com/mirusky-dev/challenge-18/services"
 )
@@ -28,63 +37,77 @@ func NewController(
 	authService services.IAuthService,
 	accountService services.IAccountService,
 	tokenService services.ITokenService,
-
+	mailService services.IMailService,
+	webhookService services.IWebhookService,
+	notificationService services.INotificationService,
+	templates mailer.TemplateRenderer,
+	broker stream.Broker,
+	config env.Config,
+	db *sql.DB,
 ) Controller {
 	return Controller{
-		userService:    userService,
-		taskService:    taskService,
-		authService:    authService,
-		accountService: accountService,
-		tokenService:   tokenService,
+		userService:         userService,
+		taskService:         taskService,
+		authService:         authService,
+		accountService:      accountService,
+		tokenService:        tokenService,
+		mailService:         mailService,
+		webhookService:      webhookService,
+		notificationService: notificationService,
+		templates:           templates,
+		broker:              broker,
+		config:              config,
+		db:                  db,
+		closing:             make(chan struct{}),
 	}
 }
 
 type Controller struct {
 	// INFO: Services and dependencies goes here
 
-	userService    services.IUserService
-	taskService    services.ITaskService
-	authService    services.IAuthService
-	accountService services.IAccountService
-	tokenService   services.ITokenService
-}
-
-func Setup(config env.Config, backgroundClient *asynq.Client) *fiber.App {
-
-	db, err := gorm.Open(mysql.Open(config.DatabaseURL), &gorm.Config{})
-	if err != nil {
-		panic(err)
-	}
-
-	mailService, _ := mailer.NewNoopMailer(config)
+	userService         services.IUserService
+	taskService         services.ITaskService
+	authService         services.IAuthService
+	accountService      services.IAccountService
+	tokenService        services.ITokenService
+	mailService         services.IMailService
+	webhookService      services.IWebhookService
+	notificationService services.INotificationService
 
-	// Dependencies Setup
-	argonPasswordHasher := core.NewArgon2IDPasswordHasher()
+	templates mailer.TemplateRenderer
+	broker    stream.Broker
 
-	refreshTokenStorage := redis.New(redis.Config{URL: config.RedisURL + "/1"})
-	tokenRevokationStorage := redis.New(redis.Config{URL: config.RedisURL + "/2"})
-	emailVerificationStorage := redis.New(redis.Config{URL: config.RedisURL + "/3"})
-	passwordResetStorage := redis.New(redis.Config{URL: config.RedisURL + "/4"})
+	// Used by the debug routes
+	config env.Config
+	db     *sql.DB
 
-	// Repositories Setup
-	userRepository := repositories.NewUserRepository(db)
-	taskRepository := repositories.NewTaskRepository(db)
+	// closing is closed on shutdown, so the streams end instead of holding it
+	closing chan struct{}
+}
 
-	// Services Setup
-	userService := services.NewUserService(userRepository, argonPasswordHasher)
-	taskService := services.NewTaskService(taskRepository, backgroundClient)
-	tokenService := services.NewTokenService(config, refreshTokenStorage, tokenRevokationStorage, userRepository)
-	accountService := services.NewAccountService(config, mailService, argonPasswordHasher, emailVerificationStorage, userRepository)
-	authService := services.NewAuthService(config, mailService, argonPasswordHasher, userRepository, passwordResetStorage, tokenService)
+// Setup creates the app from the dependencies of the container, the server is added to the lifecycle after the
+// resources it uses, so it stops before they're closed
+func Setup(deps *container.Container) *fiber.App {
+	config := deps.Config
 
 	ctrl := NewController(
-		userService,
-		taskService,
-		authService,
-		accountService,
-		tokenService,
+		deps.UserService.Get(),
+		deps.TaskService.Get(),
+		deps.AuthService.Get(),
+		deps.AccountService.Get(),
+		deps.TokenService.Get(),
+		deps.MailService.Get(),
+		deps.WebhookService.Get(),
+		deps.NotificationService.Get(),
+		deps.Templates.Get(),
+		deps.Broker.Get(),
+		config,
+		deps.SQLDB.Get(),
 	)
 
+	// Checks what the dependencies opened
+	checker := deps.Health
+
 	// New App with custom error handling
 	app := fiber.New(fiber.Config{
 		DisableStartupMessage: !config.EnableStartupMessage,
@@ -92,8 +115,13 @@ func Setup(config env.Config, backgroundClient *asynq.Client) *fiber.App {
 		ErrorHandler:          core.ErrorHandler,
 	})
 
+	// Probes are registered before the middlewares, they would flood the logs and the traces
+	app.Get("/healthz", httpHandler(http.HandlerFunc(checker.Liveness)))
+	app.Get("/readyz", httpHandler(http.HandlerFunc(checker.PublicReadiness)))
+
 	// Default middlewares
-	app.Use(logger.New())
+	app.Use(middlewares.Metrics())
+	app.Use(middlewares.RequestLogger(slog.Default()))
 	app.Use(recover.New(recover.Config{EnableStackTrace: config.EnableStackTrace}))
 
 	corsConfig := cors.ConfigDefault
@@ -104,51 +132,110 @@ func Setup(config env.Config, backgroundClient *asynq.Client) *fiber.App {
 
 	// App middlewares
 	app.Use(middlewares.Context())
+	app.Use(middlewares.Locale())
+	app.Use(middlewares.Tracing())
+
+	// Debug routes are dropped when disabled
+	routes := debugRoutes(ctrl.routes(), config)
+
+	// API description, the UI loads the document and the Redoc bundle from the same origin
+	document := describe(routes)
+	ui, err := openapi.UI(document.Info.Title, "/api/openapi.json", "/api/docs/redoc.js")
+	if err != nil {
+		panic(err)
+	}
+
+	app.Get("/api/openapi.json", func(c *fiber.Ctx) error { return c.JSON(document) })
+	app.Get("/api/docs", func(c *fiber.Ctx) error {
+		c.Type("html", "utf-8")
+		return c.Send(ui)
+	})
+	app.Get("/api/docs/redoc.js", func(c *fiber.Ctx) error {
+		c.Type("js", "utf-8")
+		return c.Send(openapi.Script())
+	})
 
 	// Routes
 	api := app.Group("/api")
 
-	v1 := api.Group("/v1")
-	debug := api.Group("/debug")
-
-	debug.Get("/ping", ctrl.pong("Pong!"))
-	debug.Get("/empty", ctrl.empty)
-	debug.Get("/friendly-error", ctrl.friendlyError)
-	debug.Get("/context", ctrl.context)
-
-	v1.Post("/auth/login", ctrl.login)
-	v1.Post("/auth/refresh-token", ctrl.refreshToken)
-	// v1.Post("/auth/register", ctrl.register)
-	v1.Post("/auth/reset-password", ctrl.sendResetPasswordLink)
-	v1.Post("/auth/reset-password/:id", ctrl.verifyResetPassword)
-	v1.Post("/accounts/email-verification/:id", ctrl.verifyEmailVerification)
-
-	{
-		// INFO: Everything under this will need auth
-		api.Use(middlewares.JWT(config))
-		api.Use(middlewares.User(ctrl.tokenService))
-
-		debug.Get("/authenticated-ping", ctrl.pong("Authenticated Pong!"))
-
-		v1.Get("/auth/logout", ctrl.logout)
-		v1.Post("/auth/logout", ctrl.logout)
-
-		v1.Get("/accounts/me", ctrl.context)
-		v1.Post("/accounts/change-password", ctrl.changePassword)
-		v1.Post("/accounts/email-verification", ctrl.sendEmailVerificationLink)
-
-		v1.Post("/users", middlewares.Authorize(core.HasRole("admin")), ctrl.createUser)
-		v1.Get("/users", middlewares.Authorize(core.HasRole("admin")), ctrl.getAllUsers)
-		v1.Get("/users/:id", middlewares.Authorize(core.HasRole("admin")), ctrl.getUserByID)
-		v1.Put("/users/:id", middlewares.Authorize(core.HasRole("admin")), ctrl.updateUser)
-		v1.Delete("/users/:id", middlewares.Authorize(core.HasRole("admin")), ctrl.deleteUser)
-
-		v1.Post("/tasks", middlewares.Authorize(core.HasRole("tech")), ctrl.createTask)
-		v1.Get("/tasks", middlewares.Authorize(core.HasRoles{Roles: []string{"tech", "manager"}}), ctrl.getAllTasks)
-		v1.Get("/tasks/:id", middlewares.Authorize(core.HasRoles{Roles: []string{"tech", "manager"}}), ctrl.getTaskByID)
-		v1.Put("/tasks/:id", middlewares.Authorize(core.HasRole("tech")), ctrl.updateTask)
-		v1.Delete("/tasks/:id", middlewares.Authorize(core.HasRole("manager")), ctrl.deleteTask)
-	}
+	register(app, filterRoutes(routes, true))
+
+	// INFO: Everything under this will need auth
+	api.Get("/v1/stream", middlewares.QueryToken())
+	api.Use(middlewares.JWT(config))
+	api.Use(middlewares.User(ctrl.tokenService))
+
+	register(app, filterRoutes(routes, false))
+
+	// The operational endpoints are served out of the public port, they expose the internals of the API
+	internal := internalServer(config, checker)
+	deps.Lifecycle.Append(lifecycle.Hook{
+		Name: "api-internal",
+		OnStart: func(ctx context.Context) error {
+			ln, err := net.Listen("tcp", internal.Addr)
+			if err != nil {
+				return err
+			}
+
+			go func() {
+				if err := internal.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					slog.Error("api: internal listener stopped", "error", err)
+				}
+			}()
+
+			return nil
+		},
+		OnStop: internal.Shutdown,
+	})
+
+	// Added last, so it's the first to stop
+	deps.Lifecycle.Append(lifecycle.Hook{
+		Name: "api",
+		OnStart: func(ctx context.Context) error {
+			// Listens here, so a taken port fails the start
+			ln, err := net.Listen("tcp", ":"+config.Port)
+			if err != nil {
+				return err
+			}
+
+			go func() {
+				if err := app.Listener(ln); err != nil {
+					slog.Error("api: listener stopped", "error", err)
+				}
+			}()
+
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			// The streams never end by themselves, the in-flight requests are drained
+			close(ctrl.closing)
+			return app.ShutdownWithTimeout(lifecycle.Timeout(ctx))
+		},
+	})
 
 	return app
 }
+
+// internalServer serves the metrics and the health checks with their errors on API_METRICS_PORT, which shouldn't be
+// reachable from outside
+func internalServer(config env.Config, checker *health.Checker) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	checker.Register(mux)
+
+	return &http.Server{
+		Addr:              ":" + config.APIMetricsPort,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+}
+
+// httpHandler serves a net/http handler, the operational endpoints are shared with the background worker
+func httpHandler(h http.Handler) fiber.Handler {
+	handler := fasthttpadaptor.NewFastHTTPHandler(h)
+
+	return func(c *fiber.Ctx) error {
+		handler(c.Context())
+		return nil
+	}
+}
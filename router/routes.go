@@ -0,0 +1,202 @@
+package router
+
+import (
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/core/background/events"
+	"github.com/mirusky-dev/challenge-18/core/env"
+	"github.com/mirusky-dev/challenge-18/core/openapi"
+	"github.com/mirusky-dev/challenge-18/models/dtos"
+	"github.com/mirusky-dev/challenge-18/models/entities"
+	"github.com/mirusky-dev/challenge-18/router/middlewares"
+)
+
+// Route is an endpoint of the API, Setup registers it and OpenAPI describes it
+type Route struct {
+	openapi.Route
+	Handler fiber.Handler
+
+	// Debug routes are only registered when enabled, see debugRoutes
+	Debug bool
+}
+
+// routes returns every endpoint, the public ones are registered before the auth middlewares.
+//
+// The debug ones are filtered by debugRoutes, outside DEV they need an admin.
+func (ctrl Controller) routes() []Route {
+	var (
+		admin         = []string{"admin"}
+		manager       = []string{"manager"}
+		tech          = []string{"tech"}
+		techOrManager = []string{"tech", "manager"}
+		unreadCount   = struct {
+			Unread int `json:"unread"`
+		}{}
+		paginated      = core.PaginationParams{}
+		notificationsQ = struct {
+			core.PaginationParams
+			Unread bool `query:"unread"` // only the unread ones
+		}{}
+	)
+
+	return []Route{
+		{Debug: true, Handler: ctrl.pong("Pong!"), Route: openapi.Route{Method: http.MethodGet, Path: "/api/debug/ping", OperationID: "ping", Public: true, Summary: "Checks the API is up", ContentType: fiber.MIMETextPlain}},
+		{Debug: true, Handler: ctrl.empty, Route: openapi.Route{Method: http.MethodGet, Path: "/api/debug/empty", Public: true, Summary: "Returns an empty response"}},
+		{Debug: true, Handler: ctrl.friendlyError, Route: openapi.Route{Method: http.MethodGet, Path: "/api/debug/friendly-error", Public: true, Summary: "Returns the error described by the query (status, code, message, error, severity)"}},
+		{Debug: true, Handler: ctrl.context, Route: openapi.Route{Method: http.MethodGet, Path: "/api/debug/context", Public: true, Summary: "Returns the request context", Response: core.UserCtx{}}},
+		{Debug: true, Handler: ctrl.mailPreview, Route: openapi.Route{Method: http.MethodGet, Path: "/api/debug/mail-preview/:name", Public: true, Summary: "Renders a mail template with sample data", Query: struct {
+			Locale string `query:"locale"`
+			Format string `query:"format"` // html (default), text or json
+		}{}, ContentType: fiber.MIMETextHTML}},
+		{Debug: true, Handler: ctrl.effectiveConfig, Route: openapi.Route{Method: http.MethodGet, Path: "/api/debug/config", OperationID: "config", Public: true, Summary: "Returns the effective config by env variable, the secrets are redacted", Response: map[string]any{}}},
+		{Debug: true, Handler: ctrl.routeTable, Route: openapi.Route{Method: http.MethodGet, Path: "/api/debug/routes", Public: true, Summary: "Lists the routes with who can call them", Response: []routeInfo{}}},
+		{Debug: true, Handler: ctrl.runtimeStats, Route: openapi.Route{Method: http.MethodGet, Path: "/api/debug/runtime", Public: true, Summary: "Returns the goroutines and memory stats", Response: runtimeInfo{}}},
+		{Debug: true, Handler: ctrl.dbStats, Route: openapi.Route{Method: http.MethodGet, Path: "/api/debug/db", Public: true, Summary: "Returns the database connection pool stats", Response: dbPoolStats{}}},
+		{Debug: true, Handler: ctrl.pprof, Route: openapi.Route{Method: http.MethodGet, Path: "/api/debug/pprof/:profile", Public: true, Summary: "Serves a runtime profile (goroutine, heap, allocs, block, mutex, threadcreate, profile, trace, cmdline or symbol) for go tool pprof", Query: struct {
+			Debug   int `query:"debug"`   // 1 or 2 for a text output, e.g: goroutine?debug=2 dumps the stacks
+			Seconds int `query:"seconds"` // of profile and trace
+		}{}, ContentType: "application/octet-stream"}},
+
+		{Handler: ctrl.login, Route: openapi.Route{Method: http.MethodPost, Path: "/api/v1/auth/login", Public: true, Summary: "Logs in with username or email", Request: dtos.Login{}, Response: dtos.Token{}}},
+		{Handler: ctrl.refreshToken, Route: openapi.Route{Method: http.MethodPost, Path: "/api/v1/auth/refresh-token", Public: true, Summary: "Exchanges the refresh token (query or cookie) for a new token", Query: struct {
+			RefreshToken string `query:"refresh_token"`
+		}{}, Response: dtos.Token{}}},
+		{Handler: ctrl.sendResetPasswordLink, Route: openapi.Route{Method: http.MethodPost, Path: "/api/v1/auth/reset-password", Public: true, Summary: "Sends a reset password link", Request: dtos.SendResetPassword{}}},
+		{Handler: ctrl.verifyResetPassword, Route: openapi.Route{Method: http.MethodPost, Path: "/api/v1/auth/reset-password/:id", Public: true, Summary: "Resets the password with the link id", Request: dtos.VerifyResetPassword{}}},
+		{Handler: ctrl.verifyEmailVerification, Route: openapi.Route{Method: http.MethodPost, Path: "/api/v1/accounts/email-verification/:id", Public: true, Summary: "Verifies the email with the link id"}},
+
+		{Debug: true, Handler: ctrl.pong("Authenticated Pong!"), Route: openapi.Route{Method: http.MethodGet, Path: "/api/debug/authenticated-ping", OperationID: "authenticatedPing", Summary: "Checks the token", ContentType: fiber.MIMETextPlain}},
+
+		{Handler: ctrl.logout, Route: openapi.Route{Method: http.MethodGet, Path: "/api/v1/auth/logout", OperationID: "logoutGet", Summary: "Revokes the token"}},
+		{Handler: ctrl.logout, Route: openapi.Route{Method: http.MethodPost, Path: "/api/v1/auth/logout", Summary: "Revokes the token"}},
+
+		{Handler: ctrl.context, Route: openapi.Route{Method: http.MethodGet, Path: "/api/v1/accounts/me", OperationID: "me", Summary: "Returns the logged user context", Response: core.UserCtx{}}},
+		{Handler: ctrl.changePassword, Route: openapi.Route{Method: http.MethodPost, Path: "/api/v1/accounts/change-password", Summary: "Changes the password", Request: dtos.ChangePassword{}}},
+		{Handler: ctrl.changeLocale, Route: openapi.Route{Method: http.MethodPut, Path: "/api/v1/accounts/locale", Summary: "Changes the locale of the emails and the errors, returns a token with it", Request: dtos.ChangeLocale{}, Response: dtos.Token{}}},
+		{Handler: ctrl.changeDigestFrequency, Route: openapi.Route{Method: http.MethodPut, Path: "/api/v1/accounts/digest-frequency", Roles: manager, Summary: "Changes how often the task emails are sent", Request: dtos.ChangeDigestFrequency{}}},
+		{Handler: ctrl.sendEmailVerificationLink, Route: openapi.Route{Method: http.MethodPost, Path: "/api/v1/accounts/email-verification", Summary: "Sends an email verification link"}},
+
+		{Handler: ctrl.getAllNotifications, Route: openapi.Route{Method: http.MethodGet, Path: "/api/v1/notifications", Summary: "Lists the notifications, newest first", Query: notificationsQ, Response: notificationPage{}}},
+		{Handler: ctrl.getUnreadNotificationsCount, Route: openapi.Route{Method: http.MethodGet, Path: "/api/v1/notifications/unread-count", Summary: "Counts the unread notifications", Response: unreadCount}},
+		{Handler: ctrl.markAllNotificationsRead, Route: openapi.Route{Method: http.MethodPost, Path: "/api/v1/notifications/read-all", Summary: "Marks every notification as read"}},
+		{Handler: ctrl.markNotificationRead, Route: openapi.Route{Method: http.MethodPost, Path: "/api/v1/notifications/:id/read", Summary: "Marks a notification as read"}},
+		{Handler: ctrl.getNotificationPreferences, Route: openapi.Route{Method: http.MethodGet, Path: "/api/v1/notifications/preferences", Summary: "Lists the channels of each event", Response: []dtos.NotificationPreference{}}},
+		{Handler: ctrl.updateNotificationPreference, Route: openapi.Route{Method: http.MethodPut, Path: "/api/v1/notifications/preferences/:event", Summary: "Changes the channels of an event", Request: dtos.UpdateNotificationPreference{}, Response: dtos.NotificationPreference{}}},
+
+		{Handler: ctrl.createUser, Route: openapi.Route{Method: http.MethodPost, Path: "/api/v1/users", Roles: admin, Summary: "Creates a user", Request: dtos.CreateUser{}, Response: userResponse{}}},
+		{Handler: ctrl.getAllUsers, Route: openapi.Route{Method: http.MethodGet, Path: "/api/v1/users", Roles: admin, Summary: "Lists the users", Query: paginated, Response: core.PagedResponse[userResponse]{}}},
+		{Handler: ctrl.getUserByID, Route: openapi.Route{Method: http.MethodGet, Path: "/api/v1/users/:id", Roles: admin, Summary: "Returns a user", Response: userResponse{}}},
+		{Handler: ctrl.updateUser, Route: openapi.Route{Method: http.MethodPut, Path: "/api/v1/users/:id", Roles: admin, Summary: "Updates a user", Request: dtos.UpdateUser{}, Response: userResponse{}}},
+		{Handler: ctrl.deleteUser, Route: openapi.Route{Method: http.MethodDelete, Path: "/api/v1/users/:id", Roles: admin, Summary: "Deletes a user"}},
+
+		{Handler: ctrl.getDeadLetterMails, Route: openapi.Route{Method: http.MethodGet, Path: "/api/v1/mails/dead-letters", Roles: admin, Summary: "Lists the mails that exhausted their retries", Query: paginated, Response: core.PagedResponse[dtos.DeadLetterMail]{}}},
+		{Handler: ctrl.retryDeadLetterMail, Route: openapi.Route{Method: http.MethodPost, Path: "/api/v1/mails/dead-letters/:id/retry", Roles: admin, Summary: "Sends a dead letter mail again"}},
+
+		{Handler: ctrl.createWebhook, Route: openapi.Route{Method: http.MethodPost, Path: "/api/v1/webhooks", Roles: admin, Summary: "Creates a webhook, the secret is only returned here", Request: dtos.CreateWebhook{}, Response: dtos.Webhook{}, Status: http.StatusCreated}},
+		{Handler: ctrl.getAllWebhooks, Route: openapi.Route{Method: http.MethodGet, Path: "/api/v1/webhooks", Roles: admin, Summary: "Lists the webhooks", Query: paginated, Response: core.PagedResponse[dtos.Webhook]{}}},
+		{Handler: ctrl.getWebhookByID, Route: openapi.Route{Method: http.MethodGet, Path: "/api/v1/webhooks/:id", Roles: admin, Summary: "Returns a webhook", Response: dtos.Webhook{}}},
+		{Handler: ctrl.updateWebhook, Route: openapi.Route{Method: http.MethodPut, Path: "/api/v1/webhooks/:id", Roles: admin, Summary: "Updates a webhook", Request: dtos.UpdateWebhook{}, Response: dtos.Webhook{}}},
+		{Handler: ctrl.deleteWebhook, Route: openapi.Route{Method: http.MethodDelete, Path: "/api/v1/webhooks/:id", Roles: admin, Summary: "Deletes a webhook"}},
+		{Handler: ctrl.getWebhookDeliveries, Route: openapi.Route{Method: http.MethodGet, Path: "/api/v1/webhooks/:id/deliveries", Roles: admin, Summary: "Lists the deliveries of a webhook", Query: paginated, Response: core.PagedResponse[dtos.WebhookDelivery]{}}},
+		{Handler: ctrl.redeliverWebhook, Route: openapi.Route{Method: http.MethodPost, Path: "/api/v1/webhooks/:id/deliveries/:deliveryId/redeliver", Roles: admin, Summary: "Sends a delivery again", Response: dtos.WebhookDelivery{}, Status: http.StatusAccepted}},
+
+		{Handler: ctrl.stream, Route: openapi.Route{Method: http.MethodGet, Path: "/api/v1/stream", Roles: techOrManager, Summary: "Streams the task changes (Server-Sent Events)", Query: struct {
+			AccessToken string `query:"access_token"` // for EventSource, which can't send the Authorization header
+		}{}, ContentType: "text/event-stream"}},
+
+		{Handler: ctrl.createTask, Route: openapi.Route{Method: http.MethodPost, Path: "/api/v1/tasks", Roles: tech, Summary: "Creates a task", Request: dtos.CreateTask{}, Response: dtos.Task{}}},
+		{Handler: ctrl.getAllTasks, Route: openapi.Route{Method: http.MethodGet, Path: "/api/v1/tasks", Roles: techOrManager, Summary: "Lists the tasks, a tech only sees its own", Query: paginated, Response: core.PagedResponse[dtos.Task]{}}},
+		{Handler: ctrl.getTaskByID, Route: openapi.Route{Method: http.MethodGet, Path: "/api/v1/tasks/:id", Roles: techOrManager, Summary: "Returns a task", Response: dtos.Task{}}},
+		{Handler: ctrl.updateTask, Route: openapi.Route{Method: http.MethodPut, Path: "/api/v1/tasks/:id", Roles: tech, Summary: "Updates or completes a task", Request: dtos.UpdateTask{}, Response: dtos.Task{}}},
+		{Handler: ctrl.deleteTask, Route: openapi.Route{Method: http.MethodDelete, Path: "/api/v1/tasks/:id", Roles: manager, Summary: "Deletes a task"}},
+	}
+}
+
+// register adds the routes to the app, guarded by their roles
+func register(app fiber.Router, routes []Route) {
+	for _, route := range routes {
+		handlers := []fiber.Handler{}
+
+		switch len(route.Roles) {
+		case 0:
+		case 1:
+			handlers = append(handlers, middlewares.Authorize(core.HasRole(route.Roles[0])))
+		default:
+			handlers = append(handlers, middlewares.Authorize(core.HasRoles{Roles: route.Roles}))
+		}
+
+		app.Add(route.Method, route.Path, append(handlers, route.Handler)...)
+	}
+}
+
+func filterRoutes(routes []Route, public bool) []Route {
+	var filtered []Route
+	for _, route := range routes {
+		if route.Public == public {
+			filtered = append(filtered, route)
+		}
+	}
+
+	return filtered
+}
+
+// OpenAPI describes the API, including the debug routes. The handlers aren't called so it doesn't need the
+// dependencies.
+func OpenAPI() openapi.Document {
+	return describe(Controller{}.routes())
+}
+
+// describe documents the routes, the API serves the one of the routes it registered
+func describe(routes []Route) openapi.Document {
+	version := env.VERSION
+	if version == "" {
+		version = "dev"
+	}
+
+	builder := openapi.NewBuilder(openapi.Info{
+		Title:       "GolangBoilerplate API",
+		Description: "Errors share the same body (`Exception`), the `code` is stable and the `message` is user friendly.",
+		Version:     version,
+	})
+
+	// The sets of values accepted by the v.In rules
+	builder.
+		Enum(dtos.NotificationEvents...).
+		Enum(append([]string{dtos.WebhookAllEvents}, events.WebhookEvents...)...).
+		Enum(entities.DigestImmediate, entities.DigestDaily, entities.DigestWeekly)
+
+	for _, route := range routes {
+		doc := route.Route
+
+		if doc.OperationID == "" {
+			doc.OperationID = handlerName(route.Handler)
+		}
+
+		// /api/v1/tasks/:id is tagged tasks, /api/debug/ping is tagged debug
+		if len(doc.Tags) == 0 {
+			segments := strings.Split(strings.TrimPrefix(doc.Path, "/api/"), "/")
+			if segments[0] == "v1" && len(segments) > 1 {
+				segments = segments[1:]
+			}
+			doc.Tags = []string{segments[0]}
+		}
+
+		builder.Add(doc)
+	}
+
+	return builder.Document()
+}
+
+// handlerName returns the method name of a controller handler, e.g: getAllTasks
+func handlerName(handler fiber.Handler) string {
+	name := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+	name = strings.TrimSuffix(name, "-fm")
+
+	return name[strings.LastIndex(name, ".")+1:]
+}
@@ -0,0 +1,88 @@
+package router
+
+import (
+	"bufio"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/mirusky-dev/challenge-18/core"
+)
+
+// streamHeartbeat keeps proxies from closing an idle connection and detects the clients that went away
+const streamHeartbeat = 15 * time.Second
+
+// stream pushes the task changes as Server-Sent Events, each client only receives what it could read from the API.
+//
+// It ends when the token expires and, checked on each heartbeat, when it's revoked, the client opens a new one with a
+// fresh token.
+func (ctrl Controller) stream(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	appCtx, ok := core.FromContext(ctx)
+	if !ok {
+		return core.MissingContext()
+	}
+
+	claims := c.Locals("jwt").(*jwt.Token).Claims.(*core.Claims)
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	messages, unsubscribe := ctrl.broker.Subscribe()
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+
+		ticker := time.NewTicker(streamHeartbeat)
+		defer ticker.Stop()
+
+		// The tokens of the API always expire, one without exp is only checked for revocation
+		var expired <-chan time.Time
+		if claims.ExpiresAt != nil {
+			timer := time.NewTimer(time.Until(claims.ExpiresAt.Time))
+			defer timer.Stop()
+			expired = timer.C
+		}
+
+		// Tells the EventSource how long to wait before reconnecting
+		fmt.Fprintf(w, "retry: %d\n\n", 3000)
+
+		for {
+			if err := w.Flush(); err != nil {
+				// The client disconnected
+				return
+			}
+
+			select {
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				if !msg.VisibleTo(appCtx) {
+					continue
+				}
+
+				fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", msg.ID, msg.Event, msg.Data)
+			case <-ticker.C:
+				if exception := ctrl.tokenService.IsRevoked(ctx, claims.ID); exception != nil {
+					return
+				}
+
+				fmt.Fprint(w, ": ping\n\n")
+			case <-expired:
+				return
+			case <-ctrl.closing:
+				// The EventSource reconnects, to another replica
+				return
+			}
+		}
+	})
+
+	return nil
+}
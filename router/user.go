@@ -1,11 +1,45 @@
 package router
 
 import (
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/mirusky-dev/challenge-18/core"
 	"github.com/mirusky-dev/challenge-18/models/dtos"
+	"github.com/mirusky-dev/challenge-18/models/entities"
 )
 
+// userResponse is the user returned by the users endpoints, the timestamps keep the time.Time format
+type userResponse struct {
+	ID              string     `json:"id"`
+	Username        string     `json:"username"`
+	Email           string     `json:"email"`
+	Role            string     `json:"role"`
+	IsEmailVerified bool       `json:"isEmailVerified"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+	DeletedAt       *time.Time `json:"deletedAt"`
+}
+
+func newUserResponse(user entities.User) userResponse {
+	res := userResponse{
+		ID:              user.ID,
+		Username:        user.Username,
+		Email:           user.Email,
+		Role:            user.Role,
+		IsEmailVerified: user.IsEmailVerified != nil && *user.IsEmailVerified,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+	}
+
+	// gorm.DeletedAt is null until the user is deleted
+	if user.DeletedAt.Valid {
+		res.DeletedAt = &user.DeletedAt.Time
+	}
+
+	return res
+}
+
 func (ctrl Controller) createUser(c *fiber.Ctx) error {
 	var dto dtos.CreateUser
 
@@ -19,16 +53,7 @@ func (ctrl Controller) createUser(c *fiber.Ctx) error {
 		return exception
 	}
 
-	return c.JSON(fiber.Map{
-		"id":              user.ID,
-		"username":        user.Username,
-		"email":           user.Email,
-		"role":            user.Role,
-		"isEmailVerified": *user.IsEmailVerified,
-		"createdAt":       user.CreatedAt,
-		"updatedAt":       user.UpdatedAt,
-		"deletedAt":       user.DeletedAt,
-	})
+	return c.JSON(newUserResponse(*user))
 }
 
 func (ctrl Controller) getUserByID(c *fiber.Ctx) error {
@@ -43,16 +68,7 @@ func (ctrl Controller) getUserByID(c *fiber.Ctx) error {
 		return core.NotFound()
 	}
 
-	return c.JSON(fiber.Map{
-		"id":              user.ID,
-		"username":        user.Username,
-		"email":           user.Email,
-		"role":            user.Role,
-		"isEmailVerified": *user.IsEmailVerified,
-		"createdAt":       user.CreatedAt,
-		"updatedAt":       user.UpdatedAt,
-		"deletedAt":       user.DeletedAt,
-	})
+	return c.JSON(newUserResponse(*user))
 }
 
 func (ctrl Controller) getAllUsers(c *fiber.Ctx) error {
@@ -69,18 +85,9 @@ func (ctrl Controller) getAllUsers(c *fiber.Ctx) error {
 		return exception
 	}
 
-	var items []fiber.Map
+	var items []userResponse
 	for _, user := range *users {
-		items = append(items, fiber.Map{
-			"id":              user.ID,
-			"username":        user.Username,
-			"email":           user.Email,
-			"role":            user.Role,
-			"isEmailVerified": *user.IsEmailVerified,
-			"createdAt":       user.CreatedAt,
-			"updatedAt":       user.UpdatedAt,
-			"deletedAt":       user.DeletedAt,
-		})
+		items = append(items, newUserResponse(user))
 	}
 
 	response := core.Page(items, int(total), *input.Limit, *input.Offset)
@@ -102,16 +109,7 @@ func (ctrl Controller) updateUser(c *fiber.Ctx) error {
 		return exception
 	}
 
-	return c.JSON(fiber.Map{
-		"id":              user.ID,
-		"username":        user.Username,
-		"email":           user.Email,
-		"role":            user.Role,
-		"isEmailVerified": *user.IsEmailVerified,
-		"createdAt":       user.CreatedAt,
-		"updatedAt":       user.UpdatedAt,
-		"deletedAt":       user.DeletedAt,
-	})
+	return c.JSON(newUserResponse(*user))
 }
 
 func (ctrl Controller) deleteUser(c *fiber.Ctx) error {
@@ -0,0 +1,135 @@
+package router
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/models/dtos"
+)
+
+func (ctrl Controller) createWebhook(c *fiber.Ctx) error {
+	var input dtos.CreateWebhook
+
+	if err := c.BodyParser(&input); err != nil {
+		return core.BadRequest(core.WithError(err))
+	}
+
+	webhook, exception := ctrl.webhookService.Create(c.UserContext(), input)
+	if exception != nil {
+		return exception
+	}
+
+	var result dtos.Webhook
+	result.FromEntity(*webhook)
+
+	// It's the only time the secret is shown, the receiver needs it to verify the signatures
+	result.Secret = webhook.Secret
+
+	return c.Status(fiber.StatusCreated).JSON(result)
+}
+
+func (ctrl Controller) getWebhookByID(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	webhook, exception := ctrl.webhookService.GetByID(c.UserContext(), id)
+	if exception != nil {
+		return exception
+	}
+
+	var result dtos.Webhook
+	result.FromEntity(*webhook)
+
+	return c.JSON(result)
+}
+
+func (ctrl Controller) getAllWebhooks(c *fiber.Ctx) error {
+	var input core.PaginationParams
+
+	if err := c.QueryParser(&input); err != nil {
+		return core.BadRequest(core.WithError(err))
+	}
+
+	input.Default()
+
+	webhooks, total, exception := ctrl.webhookService.GetAll(c.UserContext(), *input.Limit, *input.Offset)
+	if exception != nil {
+		return exception
+	}
+
+	items := []dtos.Webhook{}
+	for _, webhook := range *webhooks {
+		var item dtos.Webhook
+		item.FromEntity(webhook)
+		items = append(items, item)
+	}
+
+	response := core.Page(items, total, *input.Limit, *input.Offset)
+	return c.JSON(response)
+}
+
+func (ctrl Controller) updateWebhook(c *fiber.Ctx) error {
+	var input dtos.UpdateWebhook
+
+	if err := c.BodyParser(&input); err != nil {
+		return core.BadRequest(core.WithError(err))
+	}
+
+	id := c.Params("id")
+
+	webhook, exception := ctrl.webhookService.Update(c.UserContext(), id, input)
+	if exception != nil {
+		return exception
+	}
+
+	var result dtos.Webhook
+	result.FromEntity(*webhook)
+
+	return c.JSON(result)
+}
+
+func (ctrl Controller) deleteWebhook(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	exception := ctrl.webhookService.Delete(c.UserContext(), id)
+	if exception != nil {
+		return exception
+	}
+
+	return c.SendStatus(200)
+}
+
+func (ctrl Controller) getWebhookDeliveries(c *fiber.Ctx) error {
+	var input core.PaginationParams
+
+	if err := c.QueryParser(&input); err != nil {
+		return core.BadRequest(core.WithError(err))
+	}
+
+	input.Default()
+
+	deliveries, total, exception := ctrl.webhookService.Deliveries(c.UserContext(), c.Params("id"), *input.Limit, *input.Offset)
+	if exception != nil {
+		return exception
+	}
+
+	items := []dtos.WebhookDelivery{}
+	for _, delivery := range *deliveries {
+		var item dtos.WebhookDelivery
+		item.FromEntity(delivery)
+		items = append(items, item)
+	}
+
+	response := core.Page(items, total, *input.Limit, *input.Offset)
+	return c.JSON(response)
+}
+
+func (ctrl Controller) redeliverWebhook(c *fiber.Ctx) error {
+	delivery, exception := ctrl.webhookService.Redeliver(c.UserContext(), c.Params("id"), c.Params("deliveryId"))
+	if exception != nil {
+		return exception
+	}
+
+	var result dtos.WebhookDelivery
+	result.FromEntity(*delivery)
+
+	return c.Status(fiber.StatusAccepted).JSON(result)
+}
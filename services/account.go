@@ -2,47 +2,56 @@ package services
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	"github.com/mirusky-dev/challenge-18/core"
 	"github.com/mirusky-dev/challenge-18/core/env"
 	"github.com/mirusky-dev/challenge-18/core/mailer"
+	"github.com/mirusky-dev/challenge-18/core/tracing"
 	"github.com/mirusky-dev/challenge-18/models/dtos"
 	"github.com/mirusky-dev/challenge-18/models/entities"
 	"github.com/mirusky-dev/challenge-18/repositories"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
 )
 
 type IAccountService interface {
 	Me(ctx context.Context)
 	ChangePassword(ctx context.Context, input dtos.ChangePassword) *core.Exception
+	ChangeLocale(ctx context.Context, input dtos.ChangeLocale) *core.Exception
+	ChangeDigestFrequency(ctx context.Context, input dtos.ChangeDigestFrequency) *core.Exception
 	SendVerificationEmail(ctx context.Context, baseURL string) *core.Exception
 	VerifyCode(ctx context.Context, id string) *core.Exception
 }
 
 type accountService struct {
+	unitOfWork               core.UnitOfWork
 	userRepository           repositories.IUserRepository
 	passwordHasher           core.PasswordHasher
 	emailVerificationStorage fiber.Storage
 	mailer                   mailer.Mailer
+	templates                mailer.TemplateRenderer
 	config                   env.Config
 }
 
 func NewAccountService(
 	config env.Config,
 	mailer mailer.Mailer,
+	templates mailer.TemplateRenderer,
 	passwordHasher core.PasswordHasher,
 	emailVerificationStorage fiber.Storage,
+	unitOfWork core.UnitOfWork,
 	userRepository repositories.IUserRepository,
 ) IAccountService {
 	return &accountService{
+		unitOfWork:               unitOfWork,
 		userRepository:           userRepository,
 		passwordHasher:           passwordHasher,
 		emailVerificationStorage: emailVerificationStorage,
 		mailer:                   mailer,
+		templates:                templates,
 		config:                   config,
 	}
 }
@@ -55,17 +64,58 @@ func (svc *accountService) ChangePassword(ctx context.Context, input dtos.Change
 		return core.MissingContext()
 	}
 
-	if _, err := svc.userRepository.GetByID(ctx, appCtx.UserID()); err != nil {
-		return core.Unexpected(core.WithError(err))
-	}
-
 	hash, err := svc.passwordHasher.HashPassword(input.Password)
 	if err != nil {
 		return core.Unexpected()
 	}
 
-	if err := svc.userRepository.ChangePassword(ctx, appCtx.UserID(), hash, uuid.New().String()); err != nil {
-		return core.Unexpected()
+	// The user is read and changed in the same transaction, the hash is made before so it doesn't hold it
+	return svc.unitOfWork.Do(ctx, func(ctx context.Context) *core.Exception {
+		if _, err := svc.userRepository.GetByID(ctx, appCtx.UserID()); err != nil {
+			return core.Unexpected(core.WithError(err))
+		}
+
+		if err := svc.userRepository.ChangePassword(ctx, appCtx.UserID(), hash, uuid.New().String()); err != nil {
+			return core.Unexpected()
+		}
+
+		return nil
+	})
+}
+
+func (svc *accountService) ChangeLocale(ctx context.Context, input dtos.ChangeLocale) *core.Exception {
+	appCtx, ok := core.FromContext(ctx)
+	if !ok {
+		return core.MissingContext()
+	}
+
+	if err := input.Validate(ctx); err != nil {
+		return core.BadRequest(core.WithValidation(err))
+	}
+
+	if !slices.Contains(svc.templates.Locales(), input.Locale) {
+		return core.BadRequest(core.WithMessageKey(core.MessageUnsupportedLocale, nil))
+	}
+
+	if _, err := svc.userRepository.Update(ctx, appCtx.UserID(), entities.User{Locale: input.Locale}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (svc *accountService) ChangeDigestFrequency(ctx context.Context, input dtos.ChangeDigestFrequency) *core.Exception {
+	appCtx, ok := core.FromContext(ctx)
+	if !ok {
+		return core.MissingContext()
+	}
+
+	if err := input.Validate(ctx); err != nil {
+		return core.BadRequest(core.WithValidation(err))
+	}
+
+	if _, err := svc.userRepository.Update(ctx, appCtx.UserID(), entities.User{DigestFrequency: input.Frequency}); err != nil {
+		return err
 	}
 
 	return nil
@@ -83,24 +133,19 @@ func (svc *accountService) SendVerificationEmail(ctx context.Context, baseURL st
 	id := uuid.New().String()
 	emailVerificationLink := baseURL + "/app/verify-email/" + id
 
-	// TODO: Use Hermes
-	template := `Dear %s,
-
-Email verification link: %s
-
-Att. %s`
+	content, errRender := svc.templates.Render(mailer.TemplateEmailVerification, user.Locale, mailer.EmailVerificationData{
+		Username: user.Username,
+		Link:     emailVerificationLink,
+	})
+	if errRender != nil {
+		return core.Unexpected(core.WithError(errRender))
+	}
 
-	emailVerificationMessage := fmt.Sprintf(
-		template,
-		user.Username,
-		emailVerificationLink,
-		svc.config.EmailSenderName,
-	)
-	if err := svc.emailVerificationStorage.Set(id, []byte(user.ID), 12*time.Hour); err != nil {
+	if err := tracing.StorageContext(ctx, svc.emailVerificationStorage).Set(id, []byte(user.ID), 12*time.Hour); err != nil {
 		return core.Unexpected(core.WithError(err))
 	}
 
-	if err := svc.mailer.Send(mailer.Mail{
+	if err := mailer.SendContext(ctx, svc.mailer, mailer.Mail{
 		From: mailer.EmailInfo{
 			Name:  svc.config.EmailSenderName,
 			Email: svc.config.EmailSender,
@@ -111,8 +156,10 @@ Att. %s`
 				Email: user.Email,
 			},
 		},
-		Subject:   "Email Verification",
-		PlainText: emailVerificationMessage,
+		Subject:     content.Subject,
+		PlainText:   content.PlainText,
+		HTML:        content.HTML,
+		Attachments: content.Attachments,
 	}); err != nil {
 		return core.Unexpected(core.WithError(err))
 	}
@@ -121,17 +168,17 @@ Att. %s`
 }
 
 func (svc *accountService) VerifyCode(ctx context.Context, id string) *core.Exception {
-	value, err := svc.emailVerificationStorage.Get(id)
+	value, err := tracing.StorageContext(ctx, svc.emailVerificationStorage).Get(id)
 	if err != nil {
 		return core.Unexpected(core.WithError(err))
 	}
 
 	if value == nil {
-		return core.BadRequest(core.WithMessage("verification code expired"))
+		return core.BadRequest(core.WithMessageKey(core.MessageVerificationCodeExpired, nil))
 	}
 
 	// Prevents same code been used more than one time
-	if err := svc.emailVerificationStorage.Delete(id); err != nil {
+	if err := tracing.StorageContext(ctx, svc.emailVerificationStorage).Delete(id); err != nil {
 		return core.Unexpected(core.WithError(err))
 	}
 
@@ -2,7 +2,7 @@ package services
 
 import (
 	"context"
-	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -10,6 +10,8 @@ import (
 	"github.com/mirusky-dev/challenge-18/core"
 	"github.com/mirusky-dev/challenge-18/core/env"
 	"github.com/mirusky-dev/challenge-18/core/mailer"
+	"github.com/mirusky-dev/challenge-18/core/metrics"
+	"github.com/mirusky-dev/challenge-18/core/tracing"
 	"github.com/mirusky-dev/challenge-18/models/dtos"
 	"github.com/mirusky-dev/challenge-18/models/entities"
 	"github.com/mirusky-dev/challenge-18/repositories"
@@ -26,7 +28,9 @@ type IAuthService interface {
 
 type authService struct {
 	mailer           mailer.Mailer
+	templates        mailer.TemplateRenderer
 	passwordHasher   core.PasswordHasher
+	unitOfWork       core.UnitOfWork
 	userRepository   repositories.IUserRepository
 	resetLinkStorage fiber.Storage
 	tokenService     ITokenService // ????: Maybe it's not the best way
@@ -36,7 +40,9 @@ type authService struct {
 func NewAuthService(
 	config env.Config,
 	mailer mailer.Mailer,
+	templates mailer.TemplateRenderer,
 	passwordHasher core.PasswordHasher,
+	unitOfWork core.UnitOfWork,
 	userRepository repositories.IUserRepository,
 	resetLinkStorage fiber.Storage,
 	tokenService ITokenService,
@@ -44,7 +50,9 @@ func NewAuthService(
 	return &authService{
 		config:           config,
 		mailer:           mailer,
+		templates:        templates,
 		passwordHasher:   passwordHasher,
+		unitOfWork:       unitOfWork,
 		userRepository:   userRepository,
 		resetLinkStorage: resetLinkStorage,
 		tokenService:     tokenService,
@@ -53,12 +61,13 @@ func NewAuthService(
 
 func (svc *authService) Login(ctx context.Context, input dtos.Login) (string, string, time.Time, time.Time, *core.Exception) {
 	if err := input.Validate(ctx); err != nil {
-		return "", "", time.Time{}, time.Time{}, core.BadRequest(core.WithMessage(err.Error()))
+		return "", "", time.Time{}, time.Time{}, core.BadRequest(core.WithValidation(err))
 	}
 
 	user, errUser := svc.userRepository.FindByUsernameOrEmail(ctx, input.UsernameOrEmail, input.UsernameOrEmail)
 	if errUser != nil {
-		return "", "", time.Time{}, time.Time{}, core.Unauthorized(core.WithMessage("wrong username or password"))
+		metrics.Logins.WithLabelValues(metrics.OutcomeFailure).Inc()
+		return "", "", time.Time{}, time.Time{}, core.Unauthorized(core.WithMessageKey(core.MessageWrongCredentials, nil))
 	}
 
 	ok, err := svc.passwordHasher.VerifyPassword(input.Password, user.Password)
@@ -67,7 +76,8 @@ func (svc *authService) Login(ctx context.Context, input dtos.Login) (string, st
 	}
 
 	if !ok {
-		return "", "", time.Time{}, time.Time{}, core.Unauthorized(core.WithMessage("wrong username or password"))
+		metrics.Logins.WithLabelValues(metrics.OutcomeFailure).Inc()
+		return "", "", time.Time{}, time.Time{}, core.Unauthorized(core.WithMessageKey(core.MessageWrongCredentials, nil))
 	}
 
 	token, refreshToken, expiresAt, refreshExpiresAt, exception := svc.tokenService.Issue(ctx, user.ID)
@@ -75,6 +85,8 @@ func (svc *authService) Login(ctx context.Context, input dtos.Login) (string, st
 		return "", "", time.Time{}, time.Time{}, exception
 	}
 
+	metrics.Logins.WithLabelValues(metrics.OutcomeSuccess).Inc()
+
 	return token, refreshToken, expiresAt, refreshExpiresAt, nil
 }
 
@@ -98,12 +110,11 @@ func (svc *authService) RefreshToken(ctx context.Context, input dtos.RefreshToke
 
 func (svc *authService) Register(ctx context.Context, input dtos.CreateUser) *core.Exception {
 	if err := input.Validate(ctx); err != nil {
-		return core.BadRequest(core.WithMessage(err.Error()))
+		return core.BadRequest(core.WithValidation(err))
 	}
 
-	found, errFind := svc.userRepository.FindByUsernameOrEmail(ctx, input.Username, input.Email)
-	if errFind != nil || found.ID != "" {
-		return core.BadRequest(core.WithMessage("username or email already taken"))
+	if exception := checkAvailable(ctx, svc.userRepository, input.Username, input.Email); exception != nil {
+		return exception
 	}
 
 	hash, err := svc.passwordHasher.HashPassword(input.Password)
@@ -122,14 +133,15 @@ func (svc *authService) Register(ctx context.Context, input dtos.CreateUser) *co
 		return core.Unexpected(core.WithError(err))
 	}
 
-	// TODO: Use hermes
-	template := `Dear %s,
-
-WELCOME MESSAGE
+	content, err := svc.templates.Render(mailer.TemplateWelcome, user.Locale, mailer.WelcomeData{
+		Username: user.Username,
+	})
+	if err != nil {
+		return core.Unexpected(core.WithError(err))
+	}
 
-Att. %s`
-	welcomeMessage := fmt.Sprintf(template, user.Username, svc.config.EmailSenderName)
-	svc.mailer.Send(mailer.Mail{
+	// The user is already created, a failed welcome mail doesn't fail the registration
+	if err := mailer.SendContext(ctx, svc.mailer, mailer.Mail{
 		From: mailer.EmailInfo{
 			Name:  svc.config.EmailSenderName,
 			Email: svc.config.EmailSender,
@@ -140,9 +152,13 @@ Att. %s`
 				Email: user.Email,
 			},
 		},
-		Subject:   "Welcome to Rocket",
-		PlainText: welcomeMessage,
-	})
+		Subject:     content.Subject,
+		PlainText:   content.PlainText,
+		HTML:        content.HTML,
+		Attachments: content.Attachments,
+	}); err != nil {
+		slog.ErrorContext(ctx, "failed to send the welcome mail", "userId", user.ID, "error", err)
+	}
 
 	return nil
 }
@@ -158,14 +174,19 @@ func (svc *authService) SendResetPassword(ctx context.Context, input dtos.SendRe
 	id := uuid.New().String()
 	resetLink := input.BaseURL + "/app/reset-password/" + id
 
-	// TODO: Use Hermes
-	template := "Dear %s,\n Password reset link: %s \nAtt. %s"
-	resetPasswordMessage := fmt.Sprintf(template, user.Username, resetLink, svc.config.EmailSenderName)
-	if err := svc.resetLinkStorage.Set(id, []byte(user.ID), 12*time.Hour); err != nil {
+	content, errRender := svc.templates.Render(mailer.TemplateResetPassword, user.Locale, mailer.ResetPasswordData{
+		Username: user.Username,
+		Link:     resetLink,
+	})
+	if errRender != nil {
+		return core.Unexpected(core.WithError(errRender))
+	}
+
+	if err := tracing.StorageContext(ctx, svc.resetLinkStorage).Set(id, []byte(user.ID), 12*time.Hour); err != nil {
 		return core.Unexpected(core.WithError(err))
 	}
 
-	if err := svc.mailer.Send(mailer.Mail{
+	if err := mailer.SendContext(ctx, svc.mailer, mailer.Mail{
 		From: mailer.EmailInfo{
 			Name:  svc.config.EmailSenderName,
 			Email: svc.config.EmailSender,
@@ -176,8 +197,10 @@ func (svc *authService) SendResetPassword(ctx context.Context, input dtos.SendRe
 				Email: user.Email,
 			},
 		},
-		Subject:   "Password Recovery",
-		PlainText: resetPasswordMessage,
+		Subject:     content.Subject,
+		PlainText:   content.PlainText,
+		HTML:        content.HTML,
+		Attachments: content.Attachments,
 	}); err != nil {
 		return core.Unexpected(core.WithError(err))
 	}
@@ -187,40 +210,42 @@ func (svc *authService) SendResetPassword(ctx context.Context, input dtos.SendRe
 
 func (svc *authService) VerifyResetPassword(ctx context.Context, input dtos.VerifyResetPassword) *core.Exception {
 	if err := input.Validate(ctx); err != nil {
-		return core.BadRequest(core.WithMessage(err.Error()))
+		return core.BadRequest(core.WithValidation(err))
 	}
 
-	value, err := svc.resetLinkStorage.Get(input.ID)
+	value, err := tracing.StorageContext(ctx, svc.resetLinkStorage).Get(input.ID)
 	if err != nil {
 		return core.Unexpected(core.WithError(err))
 	}
 
 	if value == nil {
-		return core.BadRequest(core.WithMessage("reset code expired"))
+		return core.BadRequest(core.WithMessageKey(core.MessageResetCodeExpired, nil))
 	}
 
 	// Prevents same code been used more than one time
-	if err := svc.resetLinkStorage.Delete(input.ID); err != nil {
+	if err := tracing.StorageContext(ctx, svc.resetLinkStorage).Delete(input.ID); err != nil {
 		return core.Unexpected(core.WithError(err))
 	}
 
 	userID := string(value)
 
-	user, err := svc.userRepository.GetByID(ctx, userID)
-	if err != nil || user.ID != userID {
-		// user has been deleted or for some reason is inexistent
-		return core.Unexpected()
-	}
-
 	hash, err := svc.passwordHasher.HashPassword(input.Password)
 	if err != nil {
 		return core.Unexpected(core.WithError(err))
 	}
 
-	err = svc.userRepository.ChangePassword(ctx, userID, hash, uuid.New().String())
-	if err != nil {
-		return core.Unexpected(core.WithError(err))
-	}
+	// The user is read and changed in the same transaction, the reset code is in Redis and was already used up above
+	return svc.unitOfWork.Do(ctx, func(ctx context.Context) *core.Exception {
+		user, err := svc.userRepository.GetByID(ctx, userID)
+		if err != nil || user.ID != userID {
+			// user has been deleted or for some reason is inexistent
+			return core.Unexpected()
+		}
 
-	return nil
+		if err := svc.userRepository.ChangePassword(ctx, userID, hash, uuid.New().String()); err != nil {
+			return core.Unexpected(core.WithError(err))
+		}
+
+		return nil
+	})
 }
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/core/background/events"
+	"github.com/mirusky-dev/challenge-18/models/dtos"
+)
+
+type IMailService interface {
+	// DeadLetters lists the mails that exhausted their retries
+	DeadLetters(ctx context.Context, limit, offset int) ([]dtos.DeadLetterMail, int, *core.Exception)
+	// RetryDeadLetter enqueues a dead mail again
+	RetryDeadLetter(ctx context.Context, id string) *core.Exception
+}
+
+type mailService struct {
+	inspector *asynq.Inspector
+}
+
+func NewMailService(inspector *asynq.Inspector) IMailService {
+	return &mailService{
+		inspector: inspector,
+	}
+}
+
+func (svc *mailService) DeadLetters(ctx context.Context, limit, offset int) ([]dtos.DeadLetterMail, int, *core.Exception) {
+	queue, err := svc.inspector.GetQueueInfo(events.QueueMail)
+	if err != nil {
+		// Nothing was sent yet, so the queue doesn't exist
+		if errors.Is(err, asynq.ErrQueueNotFound) {
+			return []dtos.DeadLetterMail{}, 0, nil
+		}
+		return nil, 0, core.Unexpected(core.WithError(err))
+	}
+
+	if limit <= 0 || offset >= queue.Archived {
+		return []dtos.DeadLetterMail{}, queue.Archived, nil
+	}
+
+	// asynq paginates by page, so the pages of limit items that hold the offset are read. An offset that isn't a
+	// multiple of the limit starts in the middle of a page and ends in the next one
+	page, skip := offset/limit+1, offset%limit
+
+	tasks, err := svc.inspector.ListArchivedTasks(events.QueueMail, asynq.PageSize(limit), asynq.Page(page))
+	if err != nil {
+		return nil, 0, core.Unexpected(core.WithError(err))
+	}
+
+	if skip > 0 && len(tasks) == limit {
+		next, err := svc.inspector.ListArchivedTasks(events.QueueMail, asynq.PageSize(limit), asynq.Page(page+1))
+		if err != nil {
+			return nil, 0, core.Unexpected(core.WithError(err))
+		}
+		tasks = append(tasks, next...)
+	}
+
+	if skip < len(tasks) {
+		tasks = tasks[skip:]
+	} else {
+		tasks = nil
+	}
+
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+
+	items := make([]dtos.DeadLetterMail, 0, len(tasks))
+	for _, task := range tasks {
+		var p events.MailSendPayload
+		if err := json.Unmarshal(task.Payload, &p); err != nil {
+			continue
+		}
+
+		var to []string
+		for _, v := range p.Mail.To {
+			to = append(to, v.Email)
+		}
+
+		items = append(items, dtos.DeadLetterMail{
+			ID:           task.ID,
+			To:           to,
+			Subject:      p.Mail.Subject,
+			Retried:      task.Retried,
+			LastError:    task.LastErr,
+			LastFailedAt: task.LastFailedAt.Format(time.RFC3339),
+		})
+	}
+
+	return items, queue.Archived, nil
+}
+
+func (svc *mailService) RetryDeadLetter(ctx context.Context, id string) *core.Exception {
+	if err := svc.inspector.RunTask(events.QueueMail, id); err != nil {
+		if errors.Is(err, asynq.ErrTaskNotFound) || errors.Is(err, asynq.ErrQueueNotFound) {
+			return core.NotFound()
+		}
+		return core.Unexpected(core.WithError(err))
+	}
+
+	return nil
+}
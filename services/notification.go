@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/models/dtos"
+	"github.com/mirusky-dev/challenge-18/models/entities"
+	"github.com/mirusky-dev/challenge-18/repositories"
+)
+
+// INotificationService manages the notifications of the current user
+type INotificationService interface {
+	GetAll(ctx context.Context, unreadOnly bool, limit, offset int) (*[]entities.Notification, int, *core.Exception)
+	UnreadCount(ctx context.Context) (int, *core.Exception)
+	MarkRead(ctx context.Context, id string) *core.Exception
+	MarkAllRead(ctx context.Context) *core.Exception
+
+	// Preferences returns a preference for each event type, including the ones never changed
+	Preferences(ctx context.Context) (*[]entities.NotificationPreference, *core.Exception)
+	UpdatePreference(ctx context.Context, input dtos.UpdateNotificationPreference) (*entities.NotificationPreference, *core.Exception)
+}
+
+type notificationService struct {
+	notificationRepository repositories.INotificationRepository
+}
+
+func NewNotificationService(notificationRepository repositories.INotificationRepository) INotificationService {
+	return &notificationService{
+		notificationRepository: notificationRepository,
+	}
+}
+
+func (svc *notificationService) GetAll(ctx context.Context, unreadOnly bool, limit, offset int) (*[]entities.Notification, int, *core.Exception) {
+	appCtx, ok := core.FromContext(ctx)
+	if !ok {
+		return nil, 0, core.MissingContext()
+	}
+
+	notifications, total, err := svc.notificationRepository.GetAll(ctx, appCtx.UserID(), unreadOnly, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return &notifications, total, nil
+}
+
+func (svc *notificationService) UnreadCount(ctx context.Context) (int, *core.Exception) {
+	appCtx, ok := core.FromContext(ctx)
+	if !ok {
+		return 0, core.MissingContext()
+	}
+
+	return svc.notificationRepository.CountUnread(ctx, appCtx.UserID())
+}
+
+func (svc *notificationService) MarkRead(ctx context.Context, id string) *core.Exception {
+	appCtx, ok := core.FromContext(ctx)
+	if !ok {
+		return core.MissingContext()
+	}
+
+	return svc.notificationRepository.MarkRead(ctx, appCtx.UserID(), id)
+}
+
+func (svc *notificationService) MarkAllRead(ctx context.Context) *core.Exception {
+	appCtx, ok := core.FromContext(ctx)
+	if !ok {
+		return core.MissingContext()
+	}
+
+	return svc.notificationRepository.MarkAllRead(ctx, appCtx.UserID())
+}
+
+func (svc *notificationService) Preferences(ctx context.Context) (*[]entities.NotificationPreference, *core.Exception) {
+	appCtx, ok := core.FromContext(ctx)
+	if !ok {
+		return nil, core.MissingContext()
+	}
+
+	saved, err := svc.notificationRepository.GetPreferences(ctx, appCtx.UserID())
+	if err != nil {
+		return nil, err
+	}
+
+	byEvent := map[string]entities.NotificationPreference{}
+	for _, preference := range saved {
+		byEvent[preference.Event] = preference
+	}
+
+	preferences := make([]entities.NotificationPreference, 0, len(dtos.NotificationEvents))
+	for _, event := range dtos.NotificationEvents {
+		preference, ok := byEvent[event]
+		if !ok {
+			preference = entities.NotificationPreference{UserID: appCtx.UserID(), Event: event}
+		}
+		preferences = append(preferences, preference)
+	}
+
+	return &preferences, nil
+}
+
+func (svc *notificationService) UpdatePreference(ctx context.Context, input dtos.UpdateNotificationPreference) (*entities.NotificationPreference, *core.Exception) {
+	appCtx, ok := core.FromContext(ctx)
+	if !ok {
+		return nil, core.MissingContext()
+	}
+
+	// checks if it's a valid input
+	if err := input.Validate(ctx); err != nil {
+		return nil, core.BadRequest(core.WithValidation(err))
+	}
+
+	preference, err := svc.notificationRepository.GetPreference(ctx, appCtx.UserID(), input.Event)
+	if err != nil {
+		return nil, err
+	}
+
+	// Always stores both channels, so a later change of the defaults doesn't flip what the user chose
+	email, inApp := preference.WantsEmail(), preference.WantsInApp()
+	if input.Email != nil {
+		email = *input.Email
+	}
+	if input.InApp != nil {
+		inApp = *input.InApp
+	}
+	preference.Email = &email
+	preference.InApp = &inApp
+
+	preference, err = svc.notificationRepository.SavePreference(ctx, preference)
+	if err != nil {
+		return nil, err
+	}
+
+	return &preference, nil
+}
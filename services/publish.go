@@ -0,0 +1,16 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/mirusky-dev/challenge-18/core/background"
+)
+
+// publish sends the event to the subscribers (webhooks, notifications, mails). The change is already saved, so a
+// failure doesn't fail the request, it's logged since the subscribers won't know about it
+func publish(ctx context.Context, publisher background.Publisher, event background.Event) {
+	if err := publisher.Publish(ctx, event); err != nil {
+		slog.ErrorContext(ctx, "failed to publish the event", "type", event.EventType(), "error", err)
+	}
+}
@@ -2,11 +2,12 @@ package services
 
 import (
 	"context"
-	"time"
+	"log/slog"
 
-	"github.com/hibiken/asynq"
 	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/core/background"
 	"github.com/mirusky-dev/challenge-18/core/background/events"
+	"github.com/mirusky-dev/challenge-18/core/stream"
 	"github.com/mirusky-dev/challenge-18/models/dtos"
 	"github.com/mirusky-dev/challenge-18/models/entities"
 	"github.com/mirusky-dev/challenge-18/repositories"
@@ -18,14 +19,20 @@ type ITaskService interface {
 }
 
 type taskService struct {
-	taskRepository   repositories.ITaskRepository
-	backgroundClient *asynq.Client
+	clock          core.Clock
+	unitOfWork     core.UnitOfWork
+	taskRepository repositories.ITaskRepository
+	publisher      background.Publisher
+	broker         stream.Broker
 }
 
-func NewTaskService(taskRepository repositories.ITaskRepository, backgroundClient *asynq.Client) ITaskService {
+func NewTaskService(clock core.Clock, unitOfWork core.UnitOfWork, taskRepository repositories.ITaskRepository, publisher background.Publisher, broker stream.Broker) ITaskService {
 	return &taskService{
-		taskRepository:   taskRepository,
-		backgroundClient: backgroundClient,
+		clock:          clock,
+		unitOfWork:     unitOfWork,
+		taskRepository: taskRepository,
+		publisher:      publisher,
+		broker:         broker,
 	}
 }
 
@@ -36,12 +43,12 @@ func (svc *taskService) Create(ctx context.Context, input dtos.CreateTask) (*ent
 	}
 
 	if slices.Contains(appCtx.Roles(), "manager") {
-		return nil, core.BadRequest(core.WithMessage("managers can't create tasks"))
+		return nil, core.BadRequest(core.WithMessageKey(core.MessageManagersCantCreateTasks, nil))
 	}
 
 	// checks if it's a valid input
 	if err := input.Validate(ctx); err != nil {
-		return nil, core.BadRequest(core.WithMessage(err.Error()))
+		return nil, core.BadRequest(core.WithValidation(err))
 	}
 
 	task, err := svc.taskRepository.Create(ctx, entities.Task{
@@ -52,6 +59,9 @@ func (svc *taskService) Create(ctx context.Context, input dtos.CreateTask) (*ent
 		return nil, err
 	}
 
+	publish(ctx, svc.publisher, events.TaskCreated{Task: taskEvent(task)})
+	svc.stream(ctx, events.TypeTaskCreated, task)
+
 	return &task, nil
 }
 
@@ -82,42 +92,51 @@ func (svc *taskService) Update(ctx context.Context, id string, input dtos.Update
 	}
 
 	if slices.Contains(appCtx.Roles(), "manager") {
-		return nil, core.BadRequest(core.WithMessage("managers can't update tasks"))
+		return nil, core.BadRequest(core.WithMessageKey(core.MessageManagersCantUpdateTasks, nil))
 	}
 
 	// checks if it's a valid input
 	if err := input.Validate(ctx); err != nil {
-		return nil, core.BadRequest(core.WithMessage(err.Error()))
+		return nil, core.BadRequest(core.WithValidation(err))
 	}
 
-	task, err := svc.taskRepository.GetByID(ctx, id)
-	if err != nil {
-		return nil, err
-	}
+	var task entities.Task
+	var changes entities.Task
 
-	if task.UserID != appCtx.UserID() {
-		return nil, core.Forbidden()
-	}
+	// The task is read and updated in the same transaction, the events are only sent once it's committed
+	err := svc.unitOfWork.Do(ctx, func(ctx context.Context) *core.Exception {
+		var err *core.Exception
+		task, err = svc.taskRepository.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
 
-	var changes entities.Task
+		if task.UserID != appCtx.UserID() {
+			return core.Forbidden()
+		}
 
-	if input.Summary != nil {
-		changes.Summary = *input.Summary
-	}
+		if input.Summary != nil {
+			changes.Summary = *input.Summary
+		}
 
-	if task.PerformedAt == nil && input.Done != nil && *input.Done {
-		now := time.Now()
-		changes.PerformedAt = &now
-	}
+		if task.PerformedAt == nil && input.Done != nil && *input.Done {
+			now := svc.clock.Now()
+			changes.PerformedAt = &now
+		}
 
-	task, err = svc.taskRepository.Update(ctx, id, changes)
+		task, err = svc.taskRepository.Update(ctx, id, changes)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if input.Done != nil && *input.Done {
-		task, _ := events.NewTaskCompleted(task.UserID, task.ID)
-		svc.backgroundClient.Enqueue(task)
+	svc.stream(ctx, events.TypeTaskUpdated, task)
+
+	// Only when the task was completed by this update, so the manager isn't notified twice
+	if changes.PerformedAt != nil {
+		publish(ctx, svc.publisher, events.TaskCompleted{Task: taskEvent(task)})
+		svc.stream(ctx, events.TypeTaskCompleted, task)
 	}
 
 	return &task, nil
@@ -130,13 +149,60 @@ func (svc *taskService) Delete(ctx context.Context, id string) *core.Exception {
 	}
 
 	if !slices.Contains(appCtx.Roles(), "manager") {
-		return core.BadRequest(core.WithMessage("tech can't delete tasks"))
+		return core.BadRequest(core.WithMessageKey(core.MessageTechsCantDeleteTasks, nil))
 	}
 
-	err := svc.taskRepository.Delete(ctx, id)
+	var task entities.Task
+
+	// Keeps a snapshot to tell the subscribers and the stream what was deleted, it's read in the same transaction so it
+	// is the task that was deleted. A missing task has nothing to snapshot, the delete reports it
+	err := svc.unitOfWork.Do(ctx, func(ctx context.Context) *core.Exception {
+		var err *core.Exception
+		task, err = svc.taskRepository.GetByID(ctx, id)
+		if err != nil && err.Code != core.CodeNotFound {
+			return err
+		}
+
+		return svc.taskRepository.Delete(ctx, id)
+	})
 	if err != nil {
 		return err
 	}
 
+	task.ID = id
+
+	if task.UserID != "" {
+		publish(ctx, svc.publisher, events.TaskDeleted{Task: taskEvent(task)})
+	}
+
+	svc.stream(ctx, events.TypeTaskDeleted, task)
+
 	return nil
 }
+
+func taskEvent(task entities.Task) events.Task {
+	return events.Task{
+		ID:          task.ID,
+		Summary:     task.Summary,
+		UserID:      task.UserID,
+		PerformedAt: task.PerformedAt,
+		CreatedAt:   task.CreatedAt,
+		UpdatedAt:   task.UpdatedAt,
+	}
+}
+
+// stream pushes the change to the clients connected to the stream, a failure doesn't fail the request
+func (svc *taskService) stream(ctx context.Context, event string, task entities.Task) {
+	var data dtos.Task
+	data.FromEntity(task)
+
+	msg, err := stream.NewMessage(event, task.UserID, data)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to build the stream message", "type", event, "error", err)
+		return
+	}
+
+	if err := svc.broker.Publish(ctx, msg); err != nil {
+		slog.ErrorContext(ctx, "failed to stream the change", "type", event, "error", err)
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/mirusky-dev/challenge-18/core"
 	"github.com/mirusky-dev/challenge-18/core/env"
+	"github.com/mirusky-dev/challenge-18/core/tracing"
 	"github.com/mirusky-dev/challenge-18/repositories"
 )
 
@@ -24,6 +25,8 @@ type ITokenService interface {
 
 type jwtTokenService struct {
 	config env.Config
+	clock  core.Clock
+	ids    core.IDGenerator
 
 	refreshTokenStorage    fiber.Storage
 	tokenRevokationStorage fiber.Storage
@@ -32,12 +35,16 @@ type jwtTokenService struct {
 
 func NewTokenService(
 	config env.Config,
+	clock core.Clock,
+	ids core.IDGenerator,
 	refreshTokenStorage fiber.Storage,
 	tokenRevokationStorage fiber.Storage,
 	userRepository repositories.IUserRepository,
 ) ITokenService {
 	return &jwtTokenService{
 		config: config,
+		clock:  clock,
+		ids:    ids,
 
 		refreshTokenStorage:    refreshTokenStorage,
 		tokenRevokationStorage: tokenRevokationStorage,
@@ -64,11 +71,11 @@ func (svc *jwtTokenService) Issue(ctx context.Context, ref string) (string, stri
 	// Signature changes when user changes password, so all refresh token are revoked
 	if len(parts) == 2 {
 		if !strings.EqualFold(parts[1], user.Signature) {
-			return "", "", time.Time{}, time.Time{}, core.BadRequest(core.WithMessage("invalid or expired refresh token"))
+			return "", "", time.Time{}, time.Time{}, core.BadRequest(core.WithMessageKey(core.MessageInvalidRefreshToken, nil))
 		}
 	}
 
-	now := time.Now()
+	now := svc.clock.Now()
 
 	// TODO?: Get expiration from environment variables or use default?
 	expiresAt := now.Add(time.Minute * 5)
@@ -76,12 +83,13 @@ func (svc *jwtTokenService) Issue(ctx context.Context, ref string) (string, stri
 	claims := core.Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   user.ID,
-			ID:        uuid.New().String(),
+			ID:        svc.ids.NewID(),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 		},
-		Role: user.Role,
+		Role:   user.Role,
+		Locale: user.Locale,
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
@@ -90,13 +98,14 @@ func (svc *jwtTokenService) Issue(ctx context.Context, ref string) (string, stri
 		return "", "", time.Time{}, time.Time{}, core.Unexpected(core.WithError(err))
 	}
 
+	// Random, unlike the IDs it must not be guessed
 	refreshToken := uuid.New().String()
 	expiresIn := time.Hour
 	refreshExpiresAt := now.Add(expiresIn)
 
 	refreshTokenRef := fmt.Sprintf("%s;%s", user.ID, user.Signature)
 
-	if err := svc.refreshTokenStorage.Set(refreshToken, []byte(refreshTokenRef), expiresIn); err != nil {
+	if err := tracing.StorageContext(ctx, svc.refreshTokenStorage).Set(refreshToken, []byte(refreshTokenRef), expiresIn); err != nil {
 		return "", "", time.Time{}, time.Time{}, core.Unexpected(core.WithError(err))
 	}
 
@@ -106,16 +115,16 @@ func (svc *jwtTokenService) Issue(ctx context.Context, ref string) (string, stri
 // Refresh check if refresh token still valid and then generate a new one
 func (svc *jwtTokenService) Refresh(ctx context.Context, refreshToken string) (string, string, time.Time, time.Time, *core.Exception) {
 
-	refreshRef, err := svc.refreshTokenStorage.Get(refreshToken)
+	refreshRef, err := tracing.StorageContext(ctx, svc.refreshTokenStorage).Get(refreshToken)
 	if err != nil {
 		return "", "", time.Time{}, time.Time{}, core.Unexpected(core.WithError(err))
 	}
 
 	if refreshRef == nil {
-		return "", "", time.Time{}, time.Time{}, core.BadRequest(core.WithMessage("invalid or expired refresh token"))
+		return "", "", time.Time{}, time.Time{}, core.BadRequest(core.WithMessageKey(core.MessageInvalidRefreshToken, nil))
 	}
 
-	if err := svc.refreshTokenStorage.Delete(refreshToken); err != nil {
+	if err := tracing.StorageContext(ctx, svc.refreshTokenStorage).Delete(refreshToken); err != nil {
 		return "", "", time.Time{}, time.Time{}, core.Unexpected(core.WithError(err))
 	}
 
@@ -125,7 +134,7 @@ func (svc *jwtTokenService) Refresh(ctx context.Context, refreshToken string) (s
 // Revoke is responsable to mark JWT token as revoked
 func (svc *jwtTokenService) Revoke(ctx context.Context, tokenJTI string, tokenExpiresAt time.Time) *core.Exception {
 
-	err := svc.tokenRevokationStorage.Set(tokenJTI, []byte(tokenJTI), time.Until(tokenExpiresAt))
+	err := tracing.StorageContext(ctx, svc.tokenRevokationStorage).Set(tokenJTI, []byte(tokenJTI), tokenExpiresAt.Sub(svc.clock.Now()))
 	if err != nil {
 		return core.Unexpected(core.WithError(err))
 	}
@@ -136,11 +145,11 @@ func (svc *jwtTokenService) Revoke(ctx context.Context, tokenJTI string, tokenEx
 // IsRevoked ...
 func (svc *jwtTokenService) IsRevoked(ctx context.Context, tokenJTI string) *core.Exception {
 
-	value, err := svc.tokenRevokationStorage.Get(tokenJTI)
+	value, err := tracing.StorageContext(ctx, svc.tokenRevokationStorage).Get(tokenJTI)
 	if err != nil {
 		return core.Unexpected(core.WithError(err))
 	} else if value != nil {
-		return core.Forbidden(core.WithMessage("Token has been revoked"))
+		return core.Forbidden(core.WithMessageKey(core.MessageTokenRevoked, nil))
 	}
 
 	return nil
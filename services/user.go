@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/core/background"
+	"github.com/mirusky-dev/challenge-18/core/background/events"
 	"github.com/mirusky-dev/challenge-18/models/dtos"
 	"github.com/mirusky-dev/challenge-18/models/entities"
 	"github.com/mirusky-dev/challenge-18/repositories"
@@ -16,12 +18,14 @@ type IUserService interface {
 type userService struct {
 	userRepository repositories.IUserRepository
 	passwordHasher core.PasswordHasher
+	publisher      background.Publisher
 }
 
-func NewUserService(userRepository repositories.IUserRepository, passwordHasher core.PasswordHasher) IUserService {
+func NewUserService(userRepository repositories.IUserRepository, passwordHasher core.PasswordHasher, publisher background.Publisher) IUserService {
 	return &userService{
 		userRepository: userRepository,
 		passwordHasher: passwordHasher,
+		publisher:      publisher,
 	}
 }
 
@@ -29,13 +33,12 @@ func (svc *userService) Create(ctx context.Context, input dtos.CreateUser) (*ent
 
 	// checks if it's a valid input
 	if err := input.Validate(ctx); err != nil {
-		return nil, core.BadRequest(core.WithMessage(err.Error()))
+		return nil, core.BadRequest(core.WithValidation(err))
 	}
 
 	// run the creation flow
-	found, _ := svc.userRepository.FindByUsernameOrEmail(ctx, input.Username, input.Email)
-	if found.ID != "" {
-		return nil, core.BadRequest(core.WithMessage("username or email already taken"))
+	if exception := checkAvailable(ctx, svc.userRepository, input.Username, input.Email); exception != nil {
+		return nil, exception
 	}
 
 	hashedPassword, err := svc.passwordHasher.HashPassword(input.Password)
@@ -52,6 +55,8 @@ func (svc *userService) Create(ctx context.Context, input dtos.CreateUser) (*ent
 		return nil, errUser
 	}
 
+	publish(ctx, svc.publisher, events.UserCreated{User: userEvent(user)})
+
 	return &user, nil
 }
 
@@ -79,7 +84,7 @@ func (svc *userService) Update(ctx context.Context, id string, input dtos.Update
 
 	// checks if it's a valid input
 	if err := input.Validate(ctx); err != nil {
-		return nil, core.BadRequest(core.WithMessage(err.Error()))
+		return nil, core.BadRequest(core.WithValidation(err))
 	}
 
 	user, err := svc.userRepository.GetByID(ctx, id)
@@ -100,7 +105,7 @@ func (svc *userService) Update(ctx context.Context, id string, input dtos.Update
 
 	user, _ = svc.userRepository.FindByUsernameOrEmail(ctx, *input.Username, *input.Email)
 	if user.ID != id {
-		return nil, core.BadRequest(core.WithMessage("username or email already taken"))
+		return nil, core.BadRequest(core.WithMessageKey(core.MessageUsernameOrEmailTaken, nil))
 	}
 
 	user, err = svc.userRepository.Update(ctx, id, entities.User{Username: *input.Username, Email: *input.Email})
@@ -113,10 +118,43 @@ func (svc *userService) Update(ctx context.Context, id string, input dtos.Update
 
 func (svc *userService) Delete(ctx context.Context, id string) *core.Exception {
 
+	// Keeps a snapshot to tell the subscribers what was deleted
+	user, _ := svc.userRepository.GetByID(ctx, id)
+
 	err := svc.userRepository.Delete(ctx, id)
 	if err != nil {
 		return err
 	}
 
+	user.ID = id
+	publish(ctx, svc.publisher, events.UserDeleted{User: userEvent(user)})
+
 	return nil
 }
+
+func userEvent(user entities.User) events.User {
+	return events.User{
+		ID:              user.ID,
+		Username:        user.Username,
+		Email:           user.Email,
+		IsEmailVerified: user.IsEmailVerified != nil && *user.IsEmailVerified,
+		Role:            user.Role,
+		Locale:          user.Locale,
+		ManagerID:       user.ManagerID,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+	}
+}
+
+// checkAvailable returns a conflict when the username or the email belongs to a user, a failed lookup is returned as is
+func checkAvailable(ctx context.Context, userRepository repositories.IUserRepository, username, email string) *core.Exception {
+	_, exception := userRepository.FindByUsernameOrEmail(ctx, username, email)
+	switch {
+	case exception == nil:
+		return core.Conflict(core.WithMessageKey(core.MessageUsernameOrEmailTaken, nil))
+	case exception.Code == core.CodeNotFound:
+		return nil
+	default:
+		return exception
+	}
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"github.com/hibiken/asynq"
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/core/background"
+	"github.com/mirusky-dev/challenge-18/core/background/events"
+	"github.com/mirusky-dev/challenge-18/models/dtos"
+	"github.com/mirusky-dev/challenge-18/models/entities"
+	"github.com/mirusky-dev/challenge-18/repositories"
+)
+
+type IWebhookService interface {
+	core.IBaseService[string, dtos.CreateWebhook, dtos.UpdateWebhook, entities.Webhook]
+
+	// Deliveries lists the delivery log of a webhook, newest first
+	Deliveries(ctx context.Context, webhookID string, limit, offset int) (*[]entities.WebhookDelivery, int, *core.Exception)
+	// Redeliver sends the payload of a previous delivery again as a new delivery
+	Redeliver(ctx context.Context, webhookID, deliveryID string) (*entities.WebhookDelivery, *core.Exception)
+}
+
+type webhookService struct {
+	webhookRepository repositories.IWebhookRepository
+	backgroundClient  *asynq.Client
+}
+
+func NewWebhookService(webhookRepository repositories.IWebhookRepository, backgroundClient *asynq.Client) IWebhookService {
+	return &webhookService{
+		webhookRepository: webhookRepository,
+		backgroundClient:  backgroundClient,
+	}
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func (svc *webhookService) Create(ctx context.Context, input dtos.CreateWebhook) (*entities.Webhook, *core.Exception) {
+	// checks if it's a valid input
+	if err := input.Validate(ctx); err != nil {
+		return nil, core.BadRequest(core.WithValidation(err))
+	}
+
+	if input.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return nil, core.Unexpected(core.WithError(err))
+		}
+		input.Secret = secret
+	}
+
+	webhook, err := svc.webhookRepository.Create(ctx, entities.Webhook{
+		URL:    input.URL,
+		Secret: input.Secret,
+		Events: strings.Join(input.Events, ","),
+		Active: input.Active,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+func (svc *webhookService) GetByID(ctx context.Context, id string) (*entities.Webhook, *core.Exception) {
+	webhook, err := svc.webhookRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+func (svc *webhookService) GetAll(ctx context.Context, limit, offset int) (*[]entities.Webhook, int, *core.Exception) {
+	webhooks, total, err := svc.webhookRepository.GetAll(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return &webhooks, total, nil
+}
+
+func (svc *webhookService) Update(ctx context.Context, id string, input dtos.UpdateWebhook) (*entities.Webhook, *core.Exception) {
+	// checks if it's a valid input
+	if err := input.Validate(ctx); err != nil {
+		return nil, core.BadRequest(core.WithValidation(err))
+	}
+
+	if _, err := svc.webhookRepository.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	changes := entities.Webhook{
+		Events: strings.Join(input.Events, ","),
+		Active: input.Active,
+	}
+
+	if input.URL != nil {
+		changes.URL = *input.URL
+	}
+
+	if input.Secret != nil {
+		changes.Secret = *input.Secret
+	}
+
+	webhook, err := svc.webhookRepository.Update(ctx, id, changes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+func (svc *webhookService) Delete(ctx context.Context, id string) *core.Exception {
+	return svc.webhookRepository.Delete(ctx, id)
+}
+
+func (svc *webhookService) Deliveries(ctx context.Context, webhookID string, limit, offset int) (*[]entities.WebhookDelivery, int, *core.Exception) {
+	if _, err := svc.webhookRepository.GetByID(ctx, webhookID); err != nil {
+		return nil, 0, err
+	}
+
+	deliveries, total, err := svc.webhookRepository.GetDeliveries(ctx, webhookID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return &deliveries, total, nil
+}
+
+func (svc *webhookService) Redeliver(ctx context.Context, webhookID, deliveryID string) (*entities.WebhookDelivery, *core.Exception) {
+	previous, err := svc.webhookRepository.GetDeliveryByID(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if previous.WebhookID != webhookID {
+		return nil, core.NotFound()
+	}
+
+	// A new delivery keeps the log of the previous one untouched
+	delivery, err := svc.webhookRepository.CreateDelivery(ctx, entities.WebhookDelivery{
+		WebhookID: previous.WebhookID,
+		Event:     previous.Event,
+		Payload:   previous.Payload,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	task, errTask := events.NewWebhookDeliver(ctx, delivery.ID)
+	if errTask != nil {
+		return nil, core.Unexpected(core.WithError(errTask))
+	}
+
+	if _, errTask := background.Enqueue(ctx, svc.backgroundClient, task); errTask != nil {
+		return nil, core.Unexpected(core.WithError(errTask))
+	}
+
+	return &delivery, nil
+}
@@ -0,0 +1,196 @@
+// Package testkit runs the API without MySQL nor Redis: the repositories, storages, mailer and publisher are in
+// memory, so the route tests run with a plain go test.
+//
+//	app := testkit.NewTestApp(t)
+//	tech := app.Users.Seed(entities.User{Username: "tech", Role: "tech"})
+//	res := app.Request(t, http.MethodGet, "/api/v1/tasks", nil, testkit.Token(tech))
+package testkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/hibiken/asynq"
+
+	"github.com/mirusky-dev/challenge-18/container"
+	"github.com/mirusky-dev/challenge-18/core/background"
+	"github.com/mirusky-dev/challenge-18/core/env"
+	"github.com/mirusky-dev/challenge-18/core/lifecycle"
+	"github.com/mirusky-dev/challenge-18/core/mailer"
+	"github.com/mirusky-dev/challenge-18/core/stream"
+	"github.com/mirusky-dev/challenge-18/router"
+)
+
+// App is the API built from the in-memory dependencies, they're exposed to seed and assert
+type App struct {
+	*fiber.App
+
+	Config env.Config
+	Deps   *container.Container
+	Clock  *Clock
+	IDs    *IDs
+
+	Users  *UserRepository
+	Tasks  *TaskRepository
+	Mailer *mailer.MemoryMailer
+	Bus    *background.MemoryBus
+	Broker stream.Broker
+
+	RefreshTokens      *Storage
+	RevokedTokens      *Storage
+	EmailVerifications *Storage
+	PasswordResets     *Storage
+}
+
+// Option overrides a dependency before the app is built
+type Option func(*container.Container)
+
+// Config returns the config of the test app
+func Config() env.Config {
+	return env.Config{
+		Environment:     env.DEV,
+		EnableDebug:     true,
+		JWTSecret:       JWTSecret,
+		Mailer:          "memory",
+		EmailSender:     "no-reply@golangboilerplate.com",
+		EmailSenderName: "Golang Boilerplate (No Reply)",
+		MailBrandName:   "Golang Boilerplate",
+	}
+}
+
+// NewTestApp builds the full app, the options are applied after the in-memory dependencies are set.
+//
+// The webhooks, notifications and dead letter mails aren't faked: their routes need the integration harness.
+func NewTestApp(t testing.TB, options ...Option) *App {
+	t.Helper()
+
+	config := Config()
+	clock := NewClock(time.Now())
+	ids := NewIDs()
+
+	lc := lifecycle.New(slog.Default(), time.Second)
+	deps := container.New(config, "test", lc)
+
+	app := &App{
+		Config: config,
+		Deps:   deps,
+		Clock:  clock,
+		IDs:    ids,
+
+		Users:  NewUserRepository(clock, ids),
+		Tasks:  NewTaskRepository(clock, ids),
+		Mailer: mailer.NewMemoryMailer(),
+		Bus:    background.NewMemoryBus(clock, ids),
+		Broker: stream.NewMemoryBroker(clock, ids),
+
+		RefreshTokens:      NewStorage(clock),
+		RevokedTokens:      NewStorage(clock),
+		EmailVerifications: NewStorage(clock),
+		PasswordResets:     NewStorage(clock),
+	}
+
+	deps.Clock.Set(clock)
+	deps.IDs.Set(ids)
+	deps.UnitOfWork.Set(UnitOfWork{})
+	deps.UserRepository.Set(app.Users)
+	deps.TaskRepository.Set(app.Tasks)
+	deps.Mailer.Set(app.Mailer)
+	deps.Publisher.Set(app.Bus)
+	deps.Broker.Set(app.Broker)
+	deps.RefreshTokenStorage.Set(app.RefreshTokens)
+	deps.TokenRevocationStorage.Set(app.RevokedTokens)
+	deps.EmailVerificationStorage.Set(app.EmailVerifications)
+	deps.PasswordResetStorage.Set(app.PasswordResets)
+
+	// No database: the webhook and notification repositories are built but fail when used, the debug pool stats
+	// are empty
+	deps.DB.Set(nil)
+	deps.SQLDB.Set(nil)
+
+	// asynq connects on the first command, so the services using it can be built without Redis
+	redisOpt := asynq.RedisClientOpt{Addr: "127.0.0.1:0"}
+	deps.BackgroundClient.Set(asynq.NewClient(redisOpt))
+	deps.Inspector.Set(asynq.NewInspector(redisOpt))
+
+	for _, option := range options {
+		option(deps)
+	}
+
+	fiberApp, err := container.Resolve(func() *fiber.App { return router.Setup(deps) })
+	if err != nil {
+		t.Fatalf("testkit: building the app: %v", err)
+	}
+
+	app.App = fiberApp
+
+	t.Cleanup(func() {
+		_ = deps.BackgroundClient.Get().Close()
+		_ = deps.Inspector.Get().Close()
+		_ = app.Broker.Close()
+	})
+
+	return app
+}
+
+// Request sends a request to the app, see Request
+func (a *App) Request(t testing.TB, method, path string, body any, token string) *http.Response {
+	t.Helper()
+	return Request(t, a.App, method, path, body, token)
+}
+
+// Request sends a request to a fiber app, the body is encoded as JSON when it isn't a string or an io.Reader and the
+// token, when given, is sent as a Bearer
+func Request(t testing.TB, app *fiber.App, method, path string, body any, token string) *http.Response {
+	t.Helper()
+
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		reader = b
+	case string:
+		reader = bytes.NewBufferString(b)
+	default:
+		payload, err := json.Marshal(b)
+		if err != nil {
+			t.Fatalf("testkit: encoding the body: %v", err)
+		}
+		reader = bytes.NewBuffer(payload)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if reader != nil {
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	}
+
+	if token != "" {
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	}
+
+	res, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("testkit: %s %s: %v", method, path, err)
+	}
+
+	return res
+}
+
+// Decode reads the JSON body of the response into T
+func Decode[T any](t testing.TB, res *http.Response) T {
+	t.Helper()
+	defer res.Body.Close()
+
+	var v T
+	if err := json.NewDecoder(res.Body).Decode(&v); err != nil {
+		t.Fatalf("testkit: decoding the body: %v", err)
+	}
+
+	return v
+}
@@ -0,0 +1,44 @@
+package testkit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mirusky-dev/challenge-18/core"
+)
+
+// Clock is a fake clock, the time only moves when told to
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+var _ core.Clock = (*Clock)(nil)
+
+// NewClock starts the clock at now, truncated to the second like the timestamps stored by MySQL
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now.UTC().Truncate(time.Second)}
+}
+
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Advance moves the clock forward, e.g: to expire a stored token
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// Set moves the clock to t
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = t
+}
@@ -0,0 +1,28 @@
+package testkit
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/mirusky-dev/challenge-18/core"
+)
+
+// IDs generates sequential IDs shaped like UUIDs, e.g: 00000000-0000-7000-8000-000000000001
+type IDs struct {
+	mu   sync.Mutex
+	next int
+}
+
+var _ core.IDGenerator = (*IDs)(nil)
+
+func NewIDs() *IDs {
+	return &IDs{}
+}
+
+func (g *IDs) NewID() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.next++
+	return fmt.Sprintf("00000000-0000-7000-8000-%012d", g.next)
+}
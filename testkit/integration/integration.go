@@ -0,0 +1,160 @@
+// Package integration runs the API and the background worker over the real repositories, storages and queues. The
+// database is an in-memory SQLite with the migrations applied and Redis a miniredis, so the scenarios run with a
+// plain go test, without Docker.
+//
+//	env := integration.New(t)
+//	tech := integration.Seed(t, env, entities.User{Username: "tech", Role: "tech"})
+//	res := env.Request(t, http.MethodGet, "/api/v1/tasks", nil, testkit.Token(tech))
+//
+// Unlike testkit.NewTestApp, nothing is faked but the mail driver: the tokens live in Redis, the events go through
+// asynq and are handled by the worker.
+package integration
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/glebarez/sqlite"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/mirusky-dev/challenge-18/container"
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/core/env"
+	"github.com/mirusky-dev/challenge-18/core/lifecycle"
+	"github.com/mirusky-dev/challenge-18/core/mailer"
+	"github.com/mirusky-dev/challenge-18/models/entities"
+	"github.com/mirusky-dev/challenge-18/repositories"
+	"github.com/mirusky-dev/challenge-18/router"
+	"github.com/mirusky-dev/challenge-18/testkit"
+	"github.com/mirusky-dev/challenge-18/worker"
+)
+
+// Env is the API and the worker sharing a database and a Redis
+type Env struct {
+	*fiber.App
+
+	Config env.Config
+	Deps   *container.Container
+	DB     *gorm.DB
+	Redis  *miniredis.Miniredis
+
+	// Mailer is the driver of the worker, it holds the delivered mails
+	Mailer *mailer.MemoryMailer
+}
+
+// Migrate creates the tables of the entities, it mirrors the MySQL scripts in volumes/mysql which can't run on SQLite
+func Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&entities.User{},
+		&entities.Task{},
+		&entities.Webhook{},
+		&entities.WebhookDelivery{},
+		&entities.Notification{},
+		&entities.NotificationPreference{},
+	)
+}
+
+// Open opens an in-memory SQLite database with the migrations applied, the entities IDs come from ids
+func Open(t testing.TB, ids core.IDGenerator) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger:         logger.Default.LogMode(logger.Silent),
+		TranslateError: true,
+		// Like the MySQL schema, the relations aren't enforced
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatalf("integration: opening sqlite: %v", err)
+	}
+
+	if err := db.Use(repositories.NewIDPlugin(ids)); err != nil {
+		t.Fatalf("integration: opening sqlite: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("integration: opening sqlite: %v", err)
+	}
+
+	// Each connection would have its own in-memory database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	if err := Migrate(db); err != nil {
+		t.Fatalf("integration: migrating: %v", err)
+	}
+
+	return db
+}
+
+// New builds the API and starts the background worker, both are stopped when the test ends
+func New(t testing.TB) *Env {
+	t.Helper()
+
+	redis := miniredis.RunT(t)
+
+	config := testkit.Config()
+	config.RedisURL = "redis://" + redis.Addr()
+
+	lc := lifecycle.New(slog.Default(), time.Second)
+	deps := container.New(config, "test", lc)
+
+	e := &Env{
+		Config: config,
+		Deps:   deps,
+		DB:     Open(t, deps.IDs.Get()),
+		Redis:  redis,
+		Mailer: mailer.NewMemoryMailer(),
+	}
+
+	deps.DB.Set(e.DB)
+	deps.MailDriver.Set(e.Mailer)
+
+	app, err := container.Resolve(func() *fiber.App { return router.Setup(deps) })
+	if err != nil {
+		t.Fatalf("integration: building the app: %v", err)
+	}
+
+	e.App = app
+
+	svr, mux, err := worker.Setup(deps)
+	if err != nil {
+		t.Fatalf("integration: building the worker: %v", err)
+	}
+
+	if err := svr.Start(mux); err != nil {
+		t.Fatalf("integration: starting the worker: %v", err)
+	}
+
+	// The worker stops before the resources it uses are closed
+	t.Cleanup(func() {
+		svr.Shutdown()
+		_ = lc.Stop(context.Background())
+	})
+
+	return e
+}
+
+// Request sends a request to the API, see testkit.Request
+func (e *Env) Request(t testing.TB, method, path string, body any, token string) *http.Response {
+	t.Helper()
+	return testkit.Request(t, e.App, method, path, body, token)
+}
+
+// Seed inserts the entity in the database, its ID and timestamps are set by gorm
+func Seed[T any](t testing.TB, e *Env, entity T) T {
+	t.Helper()
+
+	if err := e.DB.Create(&entity).Error; err != nil {
+		t.Fatalf("integration: seeding %T: %v", entity, err)
+	}
+
+	return entity
+}
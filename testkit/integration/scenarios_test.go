@@ -0,0 +1,113 @@
+package integration_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/models/dtos"
+	"github.com/mirusky-dev/challenge-18/models/entities"
+	"github.com/mirusky-dev/challenge-18/testkit"
+	"github.com/mirusky-dev/challenge-18/testkit/integration"
+)
+
+func TestLoginRefreshLogout(t *testing.T) {
+	env := integration.New(t)
+
+	hashed, err := core.NewArgon2IDPasswordHasher().HashPassword("secret")
+	require.NoError(t, err)
+	integration.Seed(t, env, entities.User{Username: "tech", Email: "tech@company.com", Password: hashed, Role: "tech"})
+
+	res := env.Request(t, http.MethodPost, "/api/v1/auth/login", dtos.Login{UsernameOrEmail: "tech@company.com", Password: "secret"}, "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	token := testkit.Decode[dtos.Token](t, res)
+
+	res = env.Request(t, http.MethodPost, "/api/v1/auth/refresh-token?refresh_token="+token.RefreshToken, nil, "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	refreshed := testkit.Decode[dtos.Token](t, res)
+
+	res = env.Request(t, http.MethodPost, "/api/v1/auth/refresh-token?refresh_token="+token.RefreshToken, nil, "")
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode, "a refresh token is used once")
+
+	res = env.Request(t, http.MethodGet, "/api/v1/accounts/me", nil, refreshed.Token)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	res = env.Request(t, http.MethodPost, "/api/v1/auth/logout", nil, refreshed.Token)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	res = env.Request(t, http.MethodGet, "/api/v1/accounts/me", nil, refreshed.Token)
+	assert.Equal(t, http.StatusForbidden, res.StatusCode, "the token was revoked")
+
+	env.Redis.FastForward(2 * time.Hour)
+
+	res = env.Request(t, http.MethodPost, "/api/v1/auth/refresh-token?refresh_token="+refreshed.RefreshToken, nil, "")
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode, "the refresh token expired")
+}
+
+func TestTaskVisibility(t *testing.T) {
+	env := integration.New(t)
+
+	manager := integration.Seed(t, env, entities.User{Username: "manager", Email: "manager@company.com", Role: "manager"})
+	tech1 := integration.Seed(t, env, entities.User{Username: "tech1", Email: "tech1@company.com", Role: "tech", ManagerID: &manager.ID})
+	tech2 := integration.Seed(t, env, entities.User{Username: "tech2", Email: "tech2@company.com", Role: "tech", ManagerID: &manager.ID})
+
+	task1 := integration.Seed(t, env, entities.Task{Summary: "Task Tech 1", UserID: tech1.ID})
+	task2 := integration.Seed(t, env, entities.Task{Summary: "Task Tech 2", UserID: tech2.ID})
+
+	tests := []struct {
+		description string
+		user        entities.User
+		visible     []string
+		hidden      []string
+	}{
+		{description: "(Manager) Sees every task", user: manager, visible: []string{task1.ID, task2.ID}},
+		{description: "(Tech1) Sees only its task", user: tech1, visible: []string{task1.ID}, hidden: []string{task2.ID}},
+		{description: "(Tech2) Sees only its task", user: tech2, visible: []string{task2.ID}, hidden: []string{task1.ID}},
+	}
+
+	for _, test := range tests {
+		token := testkit.Token(test.user)
+
+		res := env.Request(t, http.MethodGet, "/api/v1/tasks", nil, token)
+		require.Equalf(t, http.StatusOK, res.StatusCode, test.description)
+
+		page := testkit.Decode[core.PagedResponse[dtos.Task]](t, res)
+		assert.Equalf(t, len(test.visible), page.Total, test.description)
+
+		for _, id := range test.visible {
+			res := env.Request(t, http.MethodGet, "/api/v1/tasks/"+id, nil, token)
+			assert.Equalf(t, http.StatusOK, res.StatusCode, test.description)
+		}
+
+		for _, id := range test.hidden {
+			res := env.Request(t, http.MethodGet, "/api/v1/tasks/"+id, nil, token)
+			assert.Equalf(t, http.StatusNotFound, res.StatusCode, test.description)
+
+			res = env.Request(t, http.MethodPut, "/api/v1/tasks/"+id, `{ "done": true }`, token)
+			assert.Equalf(t, http.StatusNotFound, res.StatusCode, test.description)
+		}
+	}
+}
+
+func TestTaskCompletedMail(t *testing.T) {
+	env := integration.New(t)
+
+	manager := integration.Seed(t, env, entities.User{Username: "manager", Email: "manager@company.com", Role: "manager"})
+	tech := integration.Seed(t, env, entities.User{Username: "tech", Email: "tech@company.com", Role: "tech", ManagerID: &manager.ID})
+	task := integration.Seed(t, env, entities.Task{Summary: "Fix the printer", UserID: tech.ID})
+
+	res := env.Request(t, http.MethodPut, "/api/v1/tasks/"+task.ID, `{ "done": true }`, testkit.Token(tech))
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	// The API publishes the event, the worker handles it and sends the mail
+	require.Eventually(t, func() bool { return len(env.Mailer.To(manager.Email)) == 1 }, 10*time.Second, 50*time.Millisecond)
+
+	mail := env.Mailer.To(manager.Email)[0]
+	assert.Equal(t, "Task Completed", mail.Subject)
+	assert.Contains(t, mail.PlainText, "Fix the printer")
+	assert.Empty(t, env.Mailer.To(tech.Email))
+}
@@ -0,0 +1,94 @@
+package testkit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Storage is an in-memory fiber.Storage, the expirations follow the clock
+type Storage struct {
+	clock *Clock
+
+	mu    sync.Mutex
+	items map[string]item
+}
+
+type item struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+var _ fiber.Storage = (*Storage)(nil)
+
+func NewStorage(clock *Clock) *Storage {
+	return &Storage{clock: clock, items: map[string]item{}}
+}
+
+// Get returns nil when the key doesn't exist or expired, like the Redis storage
+func (s *Storage) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok := s.items[key]
+	if !ok {
+		return nil, nil
+	}
+
+	if !it.expiresAt.IsZero() && !s.clock.Now().Before(it.expiresAt) {
+		delete(s.items, key)
+		return nil, nil
+	}
+
+	return it.value, nil
+}
+
+// Set keeps the value until exp, forever when it's 0
+func (s *Storage) Set(key string, val []byte, exp time.Duration) error {
+	if key == "" || len(val) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it := item{value: append([]byte(nil), val...)}
+	if exp > 0 {
+		it.expiresAt = s.clock.Now().Add(exp)
+	}
+
+	s.items[key] = it
+
+	return nil
+}
+
+func (s *Storage) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.items, key)
+
+	return nil
+}
+
+func (s *Storage) Reset() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items = map[string]item{}
+
+	return nil
+}
+
+func (s *Storage) Close() error {
+	return nil
+}
+
+// Len returns how many keys are stored, including the expired ones not read since
+func (s *Storage) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.items)
+}
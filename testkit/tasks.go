@@ -0,0 +1,180 @@
+package testkit
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/models/entities"
+	"github.com/mirusky-dev/challenge-18/repositories"
+)
+
+// TaskRepository is an in-memory repositories.ITaskRepository, a tech only sees its own tasks and a manager all of
+// them, like the gorm one
+type TaskRepository struct {
+	clock *Clock
+	ids   core.IDGenerator
+
+	mu    sync.Mutex
+	tasks map[string]entities.Task
+}
+
+var _ repositories.ITaskRepository = (*TaskRepository)(nil)
+
+func NewTaskRepository(clock *Clock, ids core.IDGenerator) *TaskRepository {
+	return &TaskRepository{clock: clock, ids: ids, tasks: map[string]entities.Task{}}
+}
+
+// Seed stores the task as it is, the ID and timestamps are set when empty
+func (r *TaskRepository) Seed(task entities.Task) entities.Task {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task = r.stamp(task)
+	r.tasks[task.ID] = task
+
+	return task
+}
+
+func (r *TaskRepository) stamp(task entities.Task) entities.Task {
+	if task.ID == "" {
+		task.ID = r.ids.NewID()
+	}
+
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = r.clock.Now()
+	}
+
+	if task.UpdatedAt.IsZero() {
+		task.UpdatedAt = task.CreatedAt
+	}
+
+	return task
+}
+
+// visible reports whether the user of the context can see the task
+func visible(ctx context.Context, task entities.Task) bool {
+	appCtx, ok := core.FromContext(ctx)
+	if !ok {
+		return false
+	}
+
+	return slices.Contains(appCtx.Roles(), "manager") || task.UserID == appCtx.UserID()
+}
+
+func (r *TaskRepository) sorted(keep func(entities.Task) bool) []entities.Task {
+	tasks := []entities.Task{}
+	for _, task := range r.tasks {
+		if keep(task) {
+			tasks = append(tasks, task)
+		}
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		if tasks[i].CreatedAt.Equal(tasks[j].CreatedAt) {
+			return tasks[i].ID < tasks[j].ID
+		}
+		return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+	})
+
+	return tasks
+}
+
+func (r *TaskRepository) Create(ctx context.Context, entity entities.Task) (entities.Task, *core.Exception) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entity = r.stamp(entity)
+	if _, ok := r.tasks[entity.ID]; ok {
+		return entities.Task{}, core.Conflict()
+	}
+
+	r.tasks[entity.ID] = entity
+
+	return entity, nil
+}
+
+func (r *TaskRepository) GetByID(ctx context.Context, id string) (entities.Task, *core.Exception) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, ok := r.tasks[id]
+	if !ok || !visible(ctx, task) {
+		return entities.Task{}, core.NotFound()
+	}
+
+	return task, nil
+}
+
+func (r *TaskRepository) GetAll(ctx context.Context, limit, offset int) ([]entities.Task, int, *core.Exception) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tasks := r.sorted(func(task entities.Task) bool { return visible(ctx, task) })
+
+	return paginate(tasks, limit, offset), len(tasks), nil
+}
+
+func (r *TaskRepository) Update(ctx context.Context, id string, changes entities.Task) (entities.Task, *core.Exception) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, ok := r.tasks[id]
+	if !ok || !visible(ctx, task) {
+		return entities.Task{}, core.NotFound()
+	}
+
+	if changes.Summary != "" {
+		task.Summary = changes.Summary
+	}
+
+	if changes.PerformedAt != nil {
+		performedAt := *changes.PerformedAt
+		task.PerformedAt = &performedAt
+	}
+
+	task.UpdatedAt = r.clock.Now()
+	r.tasks[id] = task
+
+	return task, nil
+}
+
+func (r *TaskRepository) Delete(ctx context.Context, id string) *core.Exception {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if task, ok := r.tasks[id]; !ok || !visible(ctx, task) {
+		return core.NotFound()
+	}
+
+	delete(r.tasks, id)
+
+	return nil
+}
+
+func (r *TaskRepository) Find(ctx context.Context, filter repositories.TaskFilter) ([]entities.Task, *core.Exception) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.sorted(func(task entities.Task) bool {
+		switch {
+		case filter.UserIDs != nil && !slices.Contains(filter.UserIDs, task.UserID):
+			return false
+		case !filter.CreatedFrom.IsZero() && task.CreatedAt.Before(filter.CreatedFrom):
+			return false
+		case !filter.CreatedTo.IsZero() && !task.CreatedAt.Before(filter.CreatedTo):
+			return false
+		case !filter.PerformedFrom.IsZero() && (task.PerformedAt == nil || task.PerformedAt.Before(filter.PerformedFrom)):
+			return false
+		case !filter.PerformedTo.IsZero() && (task.PerformedAt == nil || !task.PerformedAt.Before(filter.PerformedTo)):
+			return false
+		case filter.OnlyOpen && task.PerformedAt != nil:
+			return false
+		}
+
+		return true
+	}), nil
+}
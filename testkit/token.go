@@ -0,0 +1,46 @@
+package testkit
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/models/entities"
+)
+
+// JWTSecret signs the tokens of the test app
+const JWTSecret = "testkit-jwt-secret"
+
+// Token mints an access token of the user, valid for an hour.
+//
+// It's dated with the real time, not the Clock, since the JWT middleware checks the expiration with it.
+func Token(user entities.User) string {
+	return TokenFor(user, time.Hour)
+}
+
+// TokenFor mints an access token of the user valid for ttl, the JWT expiration has a second precision
+func TokenFor(user entities.User, ttl time.Duration) string {
+	now := time.Now()
+
+	claims := core.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.ID,
+			ID:        uuid.New().String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+		Role:   user.Role,
+		Locale: user.Locale,
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(JWTSecret))
+	if err != nil {
+		// HS256 only fails with a key that isn't []byte
+		panic(err)
+	}
+
+	return token
+}
@@ -0,0 +1,16 @@
+package testkit
+
+import (
+	"context"
+
+	"github.com/mirusky-dev/challenge-18/core"
+)
+
+// UnitOfWork calls fn right away, the in-memory repositories have no transaction to roll back
+type UnitOfWork struct{}
+
+var _ core.UnitOfWork = UnitOfWork{}
+
+func (UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) *core.Exception) *core.Exception {
+	return fn(ctx)
+}
@@ -0,0 +1,235 @@
+package testkit
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mirusky-dev/challenge-18/core"
+	"github.com/mirusky-dev/challenge-18/models/entities"
+	"github.com/mirusky-dev/challenge-18/repositories"
+)
+
+// UserRepository is an in-memory repositories.IUserRepository, the users are listed by creation
+type UserRepository struct {
+	clock *Clock
+	ids   core.IDGenerator
+
+	mu    sync.Mutex
+	users map[string]entities.User
+}
+
+var _ repositories.IUserRepository = (*UserRepository)(nil)
+
+func NewUserRepository(clock *Clock, ids core.IDGenerator) *UserRepository {
+	return &UserRepository{clock: clock, ids: ids, users: map[string]entities.User{}}
+}
+
+// Seed stores the user as it is, the ID and timestamps are set when empty
+func (r *UserRepository) Seed(user entities.User) entities.User {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user = r.stamp(user)
+	r.users[user.ID] = user
+
+	return user
+}
+
+func (r *UserRepository) stamp(user entities.User) entities.User {
+	if user.ID == "" {
+		user.ID = r.ids.NewID()
+	}
+
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = r.clock.Now()
+	}
+
+	if user.UpdatedAt.IsZero() {
+		user.UpdatedAt = user.CreatedAt
+	}
+
+	if user.IsEmailVerified == nil {
+		verified := false
+		user.IsEmailVerified = &verified
+	}
+
+	return user
+}
+
+// withManager resolves the Manager like the gorm Preload
+func (r *UserRepository) withManager(user entities.User) entities.User {
+	if user.ManagerID != nil {
+		if manager, ok := r.users[*user.ManagerID]; ok {
+			user.Manager = &manager
+		}
+	}
+
+	return user
+}
+
+func (r *UserRepository) sorted(keep func(entities.User) bool) []entities.User {
+	users := []entities.User{}
+	for _, user := range r.users {
+		if keep == nil || keep(user) {
+			users = append(users, r.withManager(user))
+		}
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].CreatedAt.Equal(users[j].CreatedAt) {
+			return users[i].ID < users[j].ID
+		}
+		return users[i].CreatedAt.Before(users[j].CreatedAt)
+	})
+
+	return users
+}
+
+func (r *UserRepository) Create(ctx context.Context, entity entities.User) (entities.User, *core.Exception) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entity = r.stamp(entity)
+	if _, ok := r.users[entity.ID]; ok {
+		return entities.User{}, core.Conflict()
+	}
+
+	r.users[entity.ID] = entity
+
+	return entity, nil
+}
+
+func (r *UserRepository) FindByUsernameOrEmail(ctx context.Context, username, email string) (entities.User, *core.Exception) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	users := r.sorted(func(user entities.User) bool {
+		return (username != "" && user.Username == username) || (email != "" && strings.EqualFold(user.Email, email))
+	})
+	if len(users) == 0 {
+		return entities.User{}, core.NotFound()
+	}
+
+	return users[0], nil
+}
+
+func (r *UserRepository) ChangePassword(ctx context.Context, userID, hashedPassword, signature string) *core.Exception {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userID]
+	if !ok {
+		return core.NotFound()
+	}
+
+	user.Password = hashedPassword
+	user.Signature = signature
+	user.UpdatedAt = r.clock.Now()
+	r.users[userID] = user
+
+	return nil
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id string) (entities.User, *core.Exception) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return entities.User{}, core.NotFound()
+	}
+
+	return r.withManager(user), nil
+}
+
+func (r *UserRepository) GetAll(ctx context.Context, limit, offset int) ([]entities.User, int, *core.Exception) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	users := r.sorted(nil)
+
+	return paginate(users, limit, offset), len(users), nil
+}
+
+func (r *UserRepository) Update(ctx context.Context, id string, changes entities.User) (entities.User, *core.Exception) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return entities.User{}, core.NotFound()
+	}
+
+	if changes.Username != "" {
+		user.Username = changes.Username
+	}
+
+	if changes.Email != "" {
+		user.Email = changes.Email
+	}
+
+	if changes.Locale != "" {
+		user.Locale = changes.Locale
+	}
+
+	if changes.DigestFrequency != "" {
+		user.DigestFrequency = changes.DigestFrequency
+	}
+
+	if changes.IsEmailVerified != nil {
+		verified := *changes.IsEmailVerified
+		user.IsEmailVerified = &verified
+	}
+
+	user.UpdatedAt = r.clock.Now()
+	r.users[id] = user
+
+	return r.withManager(user), nil
+}
+
+func (r *UserRepository) Delete(ctx context.Context, id string) *core.Exception {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[id]; !ok {
+		return core.NotFound()
+	}
+
+	delete(r.users, id)
+
+	return nil
+}
+
+func (r *UserRepository) FindByManagerID(ctx context.Context, managerID string) ([]entities.User, *core.Exception) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.sorted(func(user entities.User) bool {
+		return user.ManagerID != nil && *user.ManagerID == managerID
+	}), nil
+}
+
+func (r *UserRepository) FindByDigestFrequency(ctx context.Context, frequency string) ([]entities.User, *core.Exception) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.sorted(func(user entities.User) bool {
+		return user.DigestFrequency == frequency
+	}), nil
+}
+
+// paginate returns the page of the items, like LIMIT and OFFSET
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+
+	items = items[offset:]
+	if limit >= 0 && limit < len(items) {
+		items = items[:limit]
+	}
+
+	return items
+}
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN locale text AFTER role;
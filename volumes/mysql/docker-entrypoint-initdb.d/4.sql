@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+    id text(36) not null,
+    url text,
+    secret text,
+    events text,
+    active boolean default true,
+    created_at timestamp,
+    updated_at timestamp,
+    deleted_at timestamp,
+    PRIMARY KEY (id(36))
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id text(36) not null,
+    webhook_id text(36),
+    event text,
+    payload mediumtext,
+    attempts int default 0,
+    status_code int default 0,
+    response text,
+    error text,
+    delivered_at timestamp null,
+    created_at timestamp,
+    updated_at timestamp,
+    PRIMARY KEY (id(36))
+);
+
+CREATE INDEX webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id(36), created_at);
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS notifications (
+    id text(36) not null,
+    user_id text(36),
+    event text,
+    message text,
+    data text,
+    read_at timestamp null,
+    created_at timestamp,
+    updated_at timestamp,
+    PRIMARY KEY (id(36))
+);
+
+CREATE INDEX notifications_user_id_idx ON notifications (user_id(36), created_at);
+
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id text(36) not null,
+    event varchar(64) not null,
+    email boolean default true,
+    in_app boolean default true,
+    created_at timestamp,
+    updated_at timestamp,
+    PRIMARY KEY (user_id(36), event)
+);
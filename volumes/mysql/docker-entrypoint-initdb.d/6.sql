@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN digest_frequency text AFTER locale;
@@ -0,0 +1,74 @@
+// Package worker builds the background worker, its handlers and middlewares, from the dependencies of the container
+package worker
+
+import (
+	"log/slog"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/mirusky-dev/challenge-18/container"
+	"github.com/mirusky-dev/challenge-18/core/background"
+	"github.com/mirusky-dev/challenge-18/core/background/events"
+	"github.com/mirusky-dev/challenge-18/core/background/handlers"
+)
+
+// Setup creates the worker from the dependencies of the container, the server isn't started
+func Setup(deps *container.Container) (*asynq.Server, *asynq.ServeMux, error) {
+	svr, mux, err := background.NewServerMux(deps.Config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctrl, err := container.Resolve(func() handlers.Controller {
+		return handlers.Controller{
+			Config:           deps.Config,
+			Clock:            deps.Clock.Get(),
+			Mailer:           deps.MailDriver.Get(),
+			Templates:        deps.Templates.Get(),
+			BackgroundClient: deps.BackgroundClient.Get(),
+			WebhookSender:    deps.WebhookSender.Get(),
+
+			UserRepository:         deps.UserRepository.Get(),
+			TaskRepository:         deps.TaskRepository.Get(),
+			WebhookRepository:      deps.WebhookRepository.Get(),
+			NotificationRepository: deps.NotificationRepository.Get(),
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	idempotencyStore, err := container.Resolve(deps.IdempotencyStore.Get)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Middlewares run in order, the policies see the errors as returned by the handlers and the logs have the
+	// correlation ID of the request that enqueued the task
+	mux.Use(
+		background.Correlation(),
+		background.Tracing(),
+		background.Logging(slog.Default()),
+		background.Metrics(),
+		background.Recover(),
+		background.WithPolicies(background.Policies),
+		background.Errors(),
+		background.Idempotency(idempotencyStore, background.Policies),
+	)
+
+	bus := deps.Bus.Get()
+	ctrl.Subscribe(bus)
+	bus.Register(mux)
+
+	mux.HandleFunc(events.TypeMailSend, ctrl.HandleMailSend)
+	mux.HandleFunc(events.TypeWebhookDeliver, ctrl.HandleWebhookDeliver)
+	mux.HandleFunc(events.TypeDigestRun, ctrl.HandleDigestRun)
+	mux.HandleFunc(events.TypeDigestSend, ctrl.HandleDigestSend)
+
+	// Only used by the health checks, the worker doesn't inspect the queues
+	if _, err := container.Resolve(deps.Inspector.Get); err != nil {
+		return nil, nil, err
+	}
+
+	return svr, mux, nil
+}